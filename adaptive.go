@@ -0,0 +1,65 @@
+package bloomfilter
+
+import (
+	"sync"
+)
+
+// AdaptiveSet stores items exactly in a map until it holds more than a
+// threshold of them, then converts to a bloom filter, trading exactness for
+// bounded memory. A few items take less memory in a map than in a filter.
+type AdaptiveSet struct {
+	threshold int
+	m         int
+	k         int
+	exact     map[string]struct{}
+	bf        *BloomFilter
+	lock      sync.RWMutex
+}
+
+// NewAdaptive creates an adaptive set that converts to a bloom filter of m bits
+// and k hashing functions when more than threshold distinct items are added.
+func NewAdaptive(threshold, m, k int) *AdaptiveSet {
+	return &AdaptiveSet{
+		threshold: threshold,
+		m:         m,
+		k:         k,
+		exact:     make(map[string]struct{}),
+	}
+}
+
+// Add adds a byte array to the set
+func (as *AdaptiveSet) Add(v []byte) {
+	as.lock.Lock()
+	defer as.lock.Unlock()
+	if as.bf != nil {
+		as.bf.Add(v)
+		return
+	}
+	as.exact[string(v)] = struct{}{}
+	if len(as.exact) > as.threshold {
+		as.bf = New(as.m, as.k)
+		for key := range as.exact {
+			as.bf.Add([]byte(key))
+		}
+		as.exact = nil
+	}
+}
+
+// Test evaluates a byte array to determine whether it is in the set. The
+// answer is exact until the set converts to a bloom filter.
+func (as *AdaptiveSet) Test(v []byte) bool {
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	if as.bf != nil {
+		return as.bf.Test(v)
+	}
+	var _, ok = as.exact[string(v)]
+	return ok
+}
+
+// IsExact reports whether the set still stores its items exactly
+func (as *AdaptiveSet) IsExact() bool {
+	as.lock.RLock()
+	defer as.lock.RUnlock()
+	return as.bf == nil
+}
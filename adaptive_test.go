@@ -0,0 +1,43 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAdaptiveSet(t *testing.T) {
+	// a tiny filter, so false positives are common once converted
+	as := NewAdaptive(10, 64, 2)
+	for i := 0; i < 10; i++ {
+		as.Add([]byte(fmt.Sprint(i)))
+	}
+	as.Add([]byte("5"))
+	if !as.IsExact() {
+		t.Fatal("converted before the threshold")
+	}
+	for i := 0; i < 1000; i++ {
+		if as.Test([]byte(fmt.Sprint(i))) != (i < 10) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+	as.Add([]byte("10"))
+	if as.IsExact() {
+		t.Fatal("not converted past the threshold")
+	}
+	fp := 0
+	for i := 0; i < 1000; i++ {
+		ok := as.Test([]byte(fmt.Sprint(i)))
+		if i <= 10 && !ok {
+			t.Log(i)
+			t.Fail()
+		}
+		if i > 10 && ok {
+			fp++
+		}
+	}
+	if fp == 0 {
+		t.Log("expected false positives after conversion")
+		t.Fail()
+	}
+}
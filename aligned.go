@@ -0,0 +1,230 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// The aligned format is a 32 byte header followed by the buckets as big-endian
+// 32-bit words, zero padded to a multiple of 8 bytes:
+//
+//	offset 0  magic "BLMF"
+//	offset 4  version
+//	offset 8  m
+//	offset 12 k
+//	offset 16 flags
+//	offset 20 length of trailing metadata, see WriteWithMeta
+//	offset 24 hash seed, see WithSeed
+//
+// All header fields are big-endian 32-bit integers, except the 64-bit seed.
+// The buckets start at an 8 byte aligned offset so the format can be mapped
+// into memory and used in place. Version 1 had a 24 byte header without the
+// seed, and is still read as a filter with seed 0.
+const (
+	alignedMagic        = "BLMF"
+	alignedVersion      = 2
+	alignedHeaderSize   = 32
+	alignedV1HeaderSize = 24
+
+	flagEnhanced = 1 << 0
+	flagSummary  = 1 << 1
+	// flagHasher marks a filter that uses a custom Hasher, which cannot be stored
+	flagHasher = 1 << 2
+)
+
+// ToAlignedBytes returns the bloom filter in the aligned format, which
+// includes m, k, the seed and whether enhanced hashing or summary bits are
+// used, and can be used with NewFromMmap. A custom hasher cannot be stored, so
+// the format only records that one is used, and readers that are not given
+// the same hasher return ErrHasherNotStored.
+func (bf *BloomFilter) ToAlignedBytes() []byte {
+	var buckets = bf.lockedSnapshot()
+	var m, k, h = bf.m, bf.k, bf.hashing
+	bf.lock.RUnlock()
+	var bb = make([]byte, alignedSize(len(buckets)))
+	putAlignedHeader(bb, m, uint32(k), h.flags(), h.seed)
+	for i, bucket := range buckets {
+		binary.BigEndian.PutUint32(bb[alignedHeaderSize+i*4:], bucket)
+	}
+	return bb
+}
+
+// flags returns the aligned format flags describing h
+func (h hashing) flags() uint32 {
+	var flags uint32
+	if h.enhanced {
+		flags |= flagEnhanced
+	}
+	if h.summary {
+		flags |= flagSummary
+	}
+	if h.hasher != nil {
+		flags |= flagHasher
+	}
+	return flags
+}
+
+// ToPaddedBytes returns the bloom filter in the aligned format, zero padded to
+// exactly size bytes, so filters with the same m produce records of the same
+// size. It returns an error if the filter does not fit in size bytes.
+// NewFromMmap and ReadHeader accept padded input.
+func (bf *BloomFilter) ToPaddedBytes(size int) ([]byte, error) {
+	var bb = bf.ToAlignedBytes()
+	if len(bb) > size {
+		return nil, fmt.Errorf("bloomfilter: filter needs %d bytes, more than %d", len(bb), size)
+	}
+	var padded = make([]byte, size)
+	copy(padded, bb)
+	return padded, nil
+}
+
+// PaddedSize returns the size of the aligned format of the bloom filter rounded
+// up to a multiple of align, for use with ToPaddedBytes.
+func (bf *BloomFilter) PaddedSize(align int) int {
+	bf.lock.RLock()
+	var n = alignedSize(len(bf.buckets))
+	bf.lock.RUnlock()
+	if align > 1 && n%align != 0 {
+		n += align - n%align
+	}
+	return n
+}
+
+// alignedSize returns the size of the aligned format for n buckets
+func alignedSize(n int) int {
+	return alignedHeaderSize + (n+n%2)*4
+}
+
+func putAlignedHeader(bb []byte, m, k, flags uint32, seed uint64) {
+	copy(bb, alignedMagic)
+	binary.BigEndian.PutUint32(bb[4:], alignedVersion)
+	binary.BigEndian.PutUint32(bb[8:], m)
+	binary.BigEndian.PutUint32(bb[12:], k)
+	binary.BigEndian.PutUint32(bb[16:], flags)
+	binary.BigEndian.PutUint64(bb[24:], seed)
+}
+
+// MmapFilter is a read-only bloom filter backed directly by a byte slice in
+// the aligned format, such as a memory-mapped file. It does not support Add.
+// The caller must not modify or unmap the slice while the filter is in use.
+type MmapFilter struct {
+	m       uint32
+	k       int
+	hashing hashing
+	data    []byte
+}
+
+// NewFromMmap wraps bb, a byte slice in the aligned format, as a read-only
+// bloom filter without copying it. It returns ErrHasherNotStored if bb was
+// written by a filter with a custom hasher.
+func NewFromMmap(bb []byte) (*MmapFilter, error) {
+	h, err := parseAlignedHeader(bb)
+	if err != nil {
+		return nil, err
+	}
+	if h.flags&flagHasher != 0 {
+		return nil, ErrHasherNotStored
+	}
+	if uint64(len(bb)) < uint64(h.size)+uint64(h.m)/8 {
+		return nil, ErrInvalidFormat
+	}
+	return &MmapFilter{
+		m:       h.m,
+		k:       int(h.k),
+		hashing: h.hashing(nil),
+		data:    bb[h.size : uint32(h.size)+h.m/8],
+	}, nil
+}
+
+// alignedHeader is a parsed aligned format header
+type alignedHeader struct {
+	m, k, flags uint32
+	seed        uint64
+	// size is the size of the header, which depends on the version
+	size int
+}
+
+// hashing returns the hashing scheme described by the header, using hasher
+// if the filter was written with a custom one
+func (h alignedHeader) hashing(hasher Hasher) hashing {
+	return hashing{
+		enhanced: h.flags&flagEnhanced != 0,
+		summary:  h.flags&flagSummary != 0,
+		seed:     h.seed,
+		hasher:   hasher,
+	}
+}
+
+func parseAlignedHeader(bb []byte) (alignedHeader, error) {
+	var h alignedHeader
+	if len(bb) < alignedV1HeaderSize || string(bb[0:4]) != alignedMagic {
+		return h, ErrInvalidFormat
+	}
+	switch binary.BigEndian.Uint32(bb[4:]) {
+	case 1:
+		h.size = alignedV1HeaderSize
+	case alignedVersion:
+		if len(bb) < alignedHeaderSize {
+			return h, ErrInvalidFormat
+		}
+		h.size = alignedHeaderSize
+		h.seed = binary.BigEndian.Uint64(bb[24:])
+	default:
+		return h, ErrInvalidFormat
+	}
+	h.m = binary.BigEndian.Uint32(bb[8:])
+	h.k = binary.BigEndian.Uint32(bb[12:])
+	h.flags = binary.BigEndian.Uint32(bb[16:])
+	if h.m == 0 || h.m%32 != 0 || h.k == 0 {
+		return alignedHeader{}, ErrInvalidFormat
+	}
+	return h, nil
+}
+
+// readAlignedHeader reads an aligned format header of either version from r
+// and returns it along with its raw bytes
+func readAlignedHeader(r io.Reader) ([]byte, alignedHeader, error) {
+	var header = make([]byte, alignedV1HeaderSize, alignedHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, alignedHeader{}, unexpected(err)
+	}
+	if string(header[0:4]) == alignedMagic && binary.BigEndian.Uint32(header[4:]) == alignedVersion {
+		header = header[:alignedHeaderSize]
+		if _, err := io.ReadFull(r, header[alignedV1HeaderSize:]); err != nil {
+			return nil, alignedHeader{}, unexpected(err)
+		}
+	}
+	h, err := parseAlignedHeader(header)
+	if err != nil {
+		return nil, alignedHeader{}, err
+	}
+	return header, h, nil
+}
+
+// Test evaluates a byte array to determine whether it is (probably) in the filter
+func (mf *MmapFilter) Test(v []byte) bool {
+	for _, l := range mf.hashing.locations(v, mf.m, mf.k) {
+		if (binary.BigEndian.Uint32(mf.data[l/32*4:]) & (1 << (l % 32))) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestInt evaluates an int to determine whether it is (probably) in the filter
+func (mf *MmapFilter) TestInt(v int) bool {
+	var a = make([]byte, 4)
+	binary.BigEndian.PutUint32(a, uint32(v))
+	return mf.Test(a)
+}
+
+// ReadHeader reads only the header of a bloom filter in the aligned format from r
+// and returns its m and k, leaving the buckets unread.
+func ReadHeader(r io.Reader) (m, k int, err error) {
+	_, h, err := readAlignedHeader(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(h.m), int(h.k), nil
+}
@@ -0,0 +1,148 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestToAlignedBytes(t *testing.T) {
+	for _, m := range []int{32, 64, 1000} {
+		f := New(m, 4)
+		bb := f.ToAlignedBytes()
+		if len(bb)%8 != 0 || len(bb) < alignedHeaderSize+f.SizeBytes() {
+			t.Log(m, len(bb))
+			t.Fail()
+		}
+	}
+}
+
+func TestNewFromMmap(t *testing.T) {
+	for _, f := range []*BloomFilter{New(1000, 4), NewEnhanced(1000, 4)} {
+		f.Add([]byte("abc"))
+		f.AddInt(1)
+		mf, err := NewFromMmap(f.ToAlignedBytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mf.m != f.m || mf.k != f.k || mf.hashing != f.hashing {
+			t.Fail()
+		}
+		if !mf.Test([]byte("abc")) || !mf.TestInt(1) {
+			t.Fail()
+		}
+		if mf.Test([]byte("def")) || mf.TestInt(2) {
+			t.Fail()
+		}
+	}
+}
+
+func TestNewFromMmapInvalid(t *testing.T) {
+	bb := New(1000, 4).ToAlignedBytes()
+	for _, b := range [][]byte{
+		nil,
+		bb[:alignedHeaderSize],
+		bb[:len(bb)-8],
+		append([]byte("XXXX"), bb[4:]...),
+	} {
+		if _, err := NewFromMmap(b); err != ErrInvalidFormat {
+			t.Log(err)
+			t.Fail()
+		}
+	}
+}
+
+func TestReadHeader(t *testing.T) {
+	f := New(1000, 7)
+	r := bytes.NewReader(f.ToAlignedBytes())
+	m, k, err := ReadHeader(r)
+	if err != nil || m != 1024 || k != 7 {
+		t.Log(m, k, err)
+		t.Fail()
+	}
+	if r.Len() != len(f.ToAlignedBytes())-alignedHeaderSize {
+		t.Fail()
+	}
+	if _, _, err := ReadHeader(bytes.NewReader(f.ToBytes())); err != ErrInvalidFormat {
+		t.Log(err)
+		t.Fail()
+	}
+	if _, _, err := ReadHeader(bytes.NewReader(nil)); err != io.ErrUnexpectedEOF {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestToPaddedBytes(t *testing.T) {
+	f1 := New(1000, 4)
+	f2 := NewEnhanced(1000, 7)
+	for i := 0; i < 100; i++ {
+		f1.AddInt(i)
+	}
+	size := f1.PaddedSize(512)
+	if size != 512 || f2.PaddedSize(512) != size || f1.PaddedSize(0) != len(f1.ToAlignedBytes()) {
+		t.Log(size)
+		t.Fail()
+	}
+	b1, err1 := f1.ToPaddedBytes(size)
+	b2, err2 := f2.ToPaddedBytes(size)
+	if err1 != nil || err2 != nil || len(b1) != size || len(b2) != size {
+		t.Fail()
+	}
+	mf, err := NewFromMmap(b1)
+	if err != nil || !mf.TestInt(1) {
+		t.Fail()
+	}
+	if _, err := f1.ToPaddedBytes(100); err == nil {
+		t.Fail()
+	}
+}
+
+func TestAlignedVersion1(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	// a version 1 header is the version 2 header without the seed
+	bb := f.ToAlignedBytes()
+	v1 := append(append([]byte{}, bb[:alignedV1HeaderSize]...), bb[alignedHeaderSize:]...)
+	binary.BigEndian.PutUint32(v1[4:], 1)
+	mf, err := NewFromMmap(v1)
+	if err != nil || !mf.Test([]byte("abc")) {
+		t.Fatal(err)
+	}
+	g, _, err := ReadWithMeta(bytes.NewReader(v1))
+	if err != nil || !g.Compatible(f) || !bytes.Equal(g.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+	if m, k, err := ReadHeader(bytes.NewReader(v1)); err != nil || m != 1024 || k != 4 {
+		t.Fail()
+	}
+}
+
+func TestToAlignedBytesConcurrentReset(t *testing.T) {
+	f := New(1000, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for seed := uint64(1); seed <= 1000; seed++ {
+			f.ResetWithSeed(seed)
+			f.Add([]byte("abc"))
+			f.PaddedSize(64)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		// the header and buckets are read together, so any bits set belong to the stored seed
+		mf, err := NewFromMmap(f.ToAlignedBytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(mf.data, make([]byte, len(mf.data))) && !mf.Test([]byte("abc")) {
+			t.Fatal(mf.hashing.seed)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// SaveAll writes a named collection of bloom filters to a single archive file.
+// Each entry is stored as a length-prefixed name followed by m, k and the buckets,
+// all big-endian. Entries are written in name order. The archive cannot store
+// a seed, custom hasher, enhanced hashing or summary bits, so SaveAll returns an
+// error without creating the file if any filter uses one.
+func SaveAll(path string, filters map[string]*BloomFilter) error {
+	for name, bf := range filters {
+		bf.lock.RLock()
+		var h = bf.hashing
+		bf.lock.RUnlock()
+		if h.seed != 0 || h.hasher != nil || h.enhanced || h.summary {
+			return fmt.Errorf("bloomfilter: archive cannot store the hashing scheme of %q", name)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var w = bufio.NewWriter(f)
+	if err := writeArchive(w, filters); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeArchive(w io.Writer, filters map[string]*BloomFilter) error {
+	var names = make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var bf = filters[name]
+		var bb = bucketsToBytes(bf.snapshot(), binary.BigEndian)
+		var header = make([]byte, 4, 12+len(name))
+		binary.BigEndian.PutUint32(header, uint32(len(name)))
+		header = append(header, name...)
+		header = appendUint32(header, uint32(len(bb)*8))
+		header = appendUint32(header, uint32(bf.k))
+		if _, err := w.Write(header); err != nil {
+			return err
+		}
+		if _, err := w.Write(bb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadAll reads a named collection of bloom filters from an archive file written by SaveAll.
+func LoadAll(path string) (map[string]*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readArchive(bufio.NewReader(f))
+}
+
+func readArchive(r io.Reader) (map[string]*BloomFilter, error) {
+	var filters = map[string]*BloomFilter{}
+	var word = make([]byte, 4)
+	var name, bb bytes.Buffer
+	for {
+		if _, err := io.ReadFull(r, word); err == io.EOF {
+			return filters, nil
+		} else if err != nil {
+			return nil, err
+		}
+		name.Reset()
+		if err := readN(&name, r, int64(binary.BigEndian.Uint32(word))); err != nil {
+			return nil, err
+		}
+		var header = make([]byte, 8)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, unexpected(err)
+		}
+		var m = binary.BigEndian.Uint32(header[0:4])
+		var k = binary.BigEndian.Uint32(header[4:8])
+		if m%32 != 0 {
+			return nil, errors.New("bloomfilter: invalid archive entry size")
+		}
+		bb.Reset()
+		if err := readN(&bb, r, int64(m/8)); err != nil {
+			return nil, err
+		}
+		filters[name.String()] = NewFromBytes(bb.Bytes(), int(k))
+	}
+}
+
+func appendUint32(bb []byte, v uint32) []byte {
+	var a [4]byte
+	binary.BigEndian.PutUint32(a[:], v)
+	return append(bb, a[:]...)
+}
+
+// readN appends exactly n bytes from r to buf. CopyN grows buf as data
+// arrives, so a corrupt length cannot force a huge allocation. A short read is
+// reported as io.ErrUnexpectedEOF.
+func readN(buf *bytes.Buffer, r io.Reader, n int64) error {
+	if _, err := io.CopyN(buf, r, n); err != nil {
+		return unexpected(err)
+	}
+	return nil
+}
+
+// unexpected converts a clean EOF in the middle of a record into io.ErrUnexpectedEOF
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filters.bin")
+	filters := map[string]*BloomFilter{
+		"small":  New(32, 3),
+		"medium": New(1000, 4),
+		"large":  New(100000, 7),
+		"":       New(64, 2),
+	}
+	for name, f := range filters {
+		f.Add([]byte(name + "abc"))
+	}
+	if err := SaveAll(path, filters); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadAll(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(filters) {
+		t.Log(len(loaded))
+		t.Fail()
+	}
+	for name, f := range filters {
+		l, ok := loaded[name]
+		if !ok {
+			t.Log(name)
+			t.Fail()
+			continue
+		}
+		if l.k != f.k || !bytes.Equal(l.ToBytes(), f.ToBytes()) {
+			t.Log(name)
+			t.Fail()
+		}
+		if !l.Test([]byte(name + "abc")) {
+			t.Log(name)
+			t.Fail()
+		}
+	}
+}
+
+func TestLoadAllTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, map[string]*BloomFilter{"a": New(1000, 4)}); err != nil {
+		t.Fatal(err)
+	}
+	bb := buf.Bytes()
+	if _, err := readArchive(bytes.NewReader(bb[:len(bb)-1])); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSaveAllSeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filters.bin")
+	for _, f := range []*BloomFilter{
+		NewWithOptions(1000, 4, WithSeed(1)),
+		NewWithOptions(1000, 4, WithHasher(xorHasher(7))),
+		NewEnhanced(1000, 4),
+	} {
+		if err := SaveAll(path, map[string]*BloomFilter{"a": New(1000, 4), "b": f}); err == nil {
+			t.Fail()
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fail()
+		}
+	}
+}
+
+func TestLoadAllCorruptLength(t *testing.T) {
+	for _, bb := range [][]byte{
+		// a name length of 4 GiB
+		{0xff, 0xff, 0xff, 0xff, 'a'},
+		// a one byte name and m of 4 Gib
+		{0, 0, 0, 1, 'a', 0xff, 0xff, 0xff, 0xe0, 0, 0, 0, 4, 0},
+	} {
+		if _, err := readArchive(bytes.NewReader(bb)); err != io.ErrUnexpectedEOF {
+			t.Log(err)
+			t.Fail()
+		}
+	}
+}
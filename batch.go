@@ -0,0 +1,149 @@
+package bloomfilter
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// AddBatchReportNew adds each byte array in items to the bloom filter under a
+// single lock and reports, per item, whether adding it set at least one new bit.
+// Items are added in order, so a duplicate within the batch reports true at most once.
+func (bf *BloomFilter) AddBatchReportNew(items [][]byte) []bool {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if bf.stats {
+		atomic.AddUint64(&bf.adds, uint64(len(items)))
+	}
+	var r = make([]bool, len(items))
+	for i, v := range items {
+		r[i] = bf.add(v)
+	}
+	return r
+}
+
+// AddBatch adds each byte array in items to the bloom filter under a single lock
+func (bf *BloomFilter) AddBatch(items [][]byte) {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if bf.stats {
+		atomic.AddUint64(&bf.adds, uint64(len(items)))
+	}
+	for _, v := range items {
+		bf.add(v)
+	}
+}
+
+// AddUnique adds each distinct byte array in items to the bloom filter under a
+// single lock, skipping exact duplicates within the batch, and returns the
+// number of distinct items added. Duplicates are found before taking the lock.
+func (bf *BloomFilter) AddUnique(items [][]byte) int {
+	var seen = make(map[string]struct{}, len(items))
+	var unique = make([][]byte, 0, len(items))
+	for _, v := range items {
+		if _, ok := seen[string(v)]; !ok {
+			seen[string(v)] = struct{}{}
+			unique = append(unique, v)
+		}
+	}
+	bf.AddBatch(unique)
+	return len(unique)
+}
+
+// BuildParallel creates a new bloom filter of m bits and k hashing functions
+// holding items, splitting them across workers goroutines that each fill their
+// own filter before the filters are ORed together. The result has the same bits
+// as adding every item to one filter.
+func BuildParallel(items [][]byte, m, k, workers int) *BloomFilter {
+	if workers < 1 {
+		workers = 1
+	}
+	var parts = make([]*BloomFilter, workers)
+	var wg sync.WaitGroup
+	for w := range parts {
+		parts[w] = New(m, k)
+		var lo, hi = len(items) * w / workers, len(items) * (w + 1) / workers
+		wg.Add(1)
+		go func(bf *BloomFilter, items [][]byte) {
+			defer wg.Done()
+			for _, v := range items {
+				bf.add(v)
+			}
+		}(parts[w], items[lo:hi])
+	}
+	wg.Wait()
+	var r = parts[0]
+	for _, bf := range parts[1:] {
+		for i, bucket := range bf.buckets {
+			r.buckets[i] |= bucket
+		}
+	}
+	return r
+}
+
+// AddPacked adds the keys packed into buf to the bloom filter under a single lock.
+// Key i is buf[offsets[i]:offsets[i+1]], so n keys need n+1 offsets.
+func (bf *BloomFilter) AddPacked(buf []byte, offsets []int) {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	for i := 0; i+1 < len(offsets); i++ {
+		if bf.stats {
+			atomic.AddUint64(&bf.adds, 1)
+		}
+		bf.add(buf[offsets[i]:offsets[i+1]])
+	}
+}
+
+// BatchLocations returns the bit indexes of every item in one flat slice, k per
+// item (k+1 with WithSummaryBit), so item i occupies [i*k, (i+1)*k). All base
+// hashes are computed in one pass before any indexes are derived.
+func (bf *BloomFilter) BatchLocations(items [][]byte) []uint32 {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return bf.batchLocations(items)
+}
+
+func (bf *BloomFilter) batchLocations(items [][]byte) []uint32 {
+	var as = make([]uint32, len(items))
+	var bs = make([]uint32, len(items))
+	for i, v := range items {
+		as[i], bs[i] = bf.hashing.baseHashes(v)
+	}
+	var n = bf.hashing.size(bf.k)
+	var r = make([]uint32, len(items)*n)
+	for i := range items {
+		bf.hashing.fill(r[i*n:(i+1)*n], as[i], bs[i], bf.m)
+	}
+	return r
+}
+
+// TestBatch evaluates each byte array in items under a single lock to determine
+// whether it is (probably) in the bloom filter
+func (bf *BloomFilter) TestBatch(items [][]byte) []bool {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var loc = bf.batchLocations(items)
+	var n = bf.hashing.size(bf.k)
+	var r = make([]bool, len(items))
+	var positives uint64
+	for i, v := range items {
+		if present, ok := bf.overrides[string(v)]; ok {
+			r[i] = present
+		} else {
+			r[i] = true
+			for _, l := range loc[i*n : (i+1)*n] {
+				if (bf.buckets[l/32] & (1 << (l % 32))) == 0 {
+					r[i] = false
+					break
+				}
+			}
+		}
+		if r[i] {
+			positives++
+		}
+	}
+	if bf.stats {
+		atomic.AddUint64(&bf.tests, uint64(len(items)))
+		atomic.AddUint64(&bf.positives, positives)
+	}
+	return r
+}
@@ -0,0 +1,193 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestAddBatchReportNew(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	items := [][]byte{
+		[]byte("abc"),
+		[]byte("def"),
+		[]byte("ghi"),
+		[]byte("def"),
+		[]byte("ghi"),
+	}
+	expected := []bool{false, true, true, false, false}
+	actual := f.AddBatchReportNew(items)
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Log(i, expected[i], actual[i])
+			t.Fail()
+		}
+	}
+	for _, v := range items {
+		if !f.Test(v) {
+			t.Fail()
+		}
+	}
+}
+
+func TestAddPacked(t *testing.T) {
+	items := [][]byte{
+		[]byte("abc"),
+		[]byte(""),
+		[]byte("defg"),
+		[]byte("h"),
+	}
+	buf := []byte{}
+	offsets := []int{0}
+	for _, v := range items {
+		buf = append(buf, v...)
+		offsets = append(offsets, len(buf))
+	}
+	f1 := New(1000, 4)
+	f1.AddBatch(items)
+	f2 := New(1000, 4)
+	f2.AddPacked(buf, offsets)
+	if !bytes.Equal(f1.ToBytes(), f2.ToBytes()) {
+		t.Fail()
+	}
+	for _, v := range items {
+		if !f2.Test(v) {
+			t.Log(string(v))
+			t.Fail()
+		}
+	}
+}
+
+func TestBatchLocations(t *testing.T) {
+	items := [][]byte{[]byte("abc"), []byte(""), []byte(jabberwocky)}
+	for _, f := range []*BloomFilter{New(1000, 4), NewEnhanced(1000, 7), NewWithOptions(1000, 3, WithSeed(9)), NewWithOptions(1000, 3, WithSummaryBit())} {
+		loc := f.BatchLocations(items)
+		if len(loc) != len(items)*len(f.locations(nil)) {
+			t.Fatal(len(loc))
+		}
+		for i, v := range items {
+			n := len(f.locations(v))
+			if !reflect.DeepEqual(loc[i*n:(i+1)*n], f.locations(v)) {
+				t.Log(i)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestTestBatch(t *testing.T) {
+	f := New(1000, 4)
+	items := make([][]byte, 100)
+	for i := range items {
+		items[i] = []byte(fmt.Sprint(i))
+		if i%3 == 0 {
+			f.Add(items[i])
+		}
+	}
+	for i, ok := range f.TestBatch(items) {
+		if ok != f.Test(items[i]) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+}
+
+func benchmarkItems() (*BloomFilter, [][]byte) {
+	m, k := EstimateParameters(10000, 1e-4)
+	f := New(m, k)
+	items := make([][]byte, 1000)
+	for i := range items {
+		items[i] = make([]byte, 32)
+		binary.BigEndian.PutUint32(items[i], uint32(i))
+		if i%2 == 0 {
+			f.Add(items[i])
+		}
+	}
+	return f, items
+}
+
+func BenchmarkTestEach(b *testing.B) {
+	f, items := benchmarkItems()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, v := range items {
+			f.Test(v)
+		}
+	}
+}
+
+func BenchmarkTestBatch(b *testing.B) {
+	f, items := benchmarkItems()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.TestBatch(items)
+	}
+}
+
+func TestAddUnique(t *testing.T) {
+	var added int
+	f := NewWithOptions(1000, 4, WithKeySink(func([]byte) { added++ }))
+	items := make([][]byte, 100)
+	for i := range items {
+		items[i] = []byte(fmt.Sprint(i % 50))
+	}
+	if n := f.AddUnique(items); n != 50 || added != 50 {
+		t.Log(n, added)
+		t.Fail()
+	}
+	for _, v := range items {
+		if !f.Test(v) {
+			t.Fail()
+		}
+	}
+	if f.AddUnique(nil) != 0 {
+		t.Fail()
+	}
+}
+
+func TestBuildParallel(t *testing.T) {
+	items := make([][]byte, 1001)
+	for i := range items {
+		items[i] = []byte(fmt.Sprint(i))
+	}
+	f := New(10000, 4)
+	f.AddBatch(items)
+	for _, workers := range []int{0, 1, 3, 8} {
+		p := BuildParallel(items, 10000, 4, workers)
+		if !bytes.Equal(p.ToBytes(), f.ToBytes()) {
+			t.Log(workers)
+			t.Fail()
+		}
+	}
+}
+
+func bulkItems() [][]byte {
+	items := make([][]byte, 100000)
+	for i := range items {
+		items[i] = make([]byte, 16)
+		binary.BigEndian.PutUint32(items[i], uint32(i))
+	}
+	return items
+}
+
+func BenchmarkBuildAddBatch(b *testing.B) {
+	items := bulkItems()
+	m, k := EstimateParameters(len(items), 1e-3)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New(m, k).AddBatch(items)
+	}
+}
+
+func BenchmarkBuildParallel(b *testing.B) {
+	items := bulkItems()
+	m, k := EstimateParameters(len(items), 1e-3)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildParallel(items, m, k, runtime.GOMAXPROCS(0))
+	}
+}
@@ -0,0 +1,53 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler using the aligned format
+// of ToAlignedBytes, so m, k, the seed and the hashing scheme travel with the
+// buckets. A custom hasher is recorded but not stored.
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	return bf.ToAlignedBytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It replaces the
+// contents, m, k, seed and hashing scheme of bf with those of data in the
+// aligned format, keeping bf's other options, and can be used on a zero
+// BloomFilter. Data written with a custom hasher can only be read into a
+// filter configured with the same hasher; it returns ErrHasherNotStored if bf
+// has none, and ErrHashMismatch if bf has a hasher but data was not written
+// with one. With WithExactCount the count becomes ApproximateCount.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	h, err := parseAlignedHeader(data)
+	if err != nil {
+		return err
+	}
+	if uint64(len(data)) < uint64(h.size)+uint64(h.m)/8 {
+		return ErrInvalidFormat
+	}
+	var buckets = make([]uint32, h.m/32)
+	for i := range buckets {
+		buckets[i] = binary.BigEndian.Uint32(data[h.size+i*4:])
+	}
+	if bf.lock == nil {
+		bf.lock = &sync.RWMutex{}
+	}
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	switch {
+	case h.flags&flagHasher != 0 && bf.hashing.hasher == nil:
+		return ErrHasherNotStored
+	case h.flags&flagHasher == 0 && bf.hashing.hasher != nil:
+		return ErrHashMismatch
+	}
+	bf.m = h.m
+	bf.k = int(h.k)
+	bf.hashing = h.hashing(bf.hashing.hasher)
+	bf.buckets = buckets
+	if bf.exactCount {
+		bf.count = approximateCount(popCount(buckets), h.m, bf.hashing.size(bf.k))
+	}
+	return nil
+}
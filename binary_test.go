@@ -0,0 +1,107 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"testing"
+)
+
+var _ encoding.BinaryMarshaler = (*BloomFilter)(nil)
+var _ encoding.BinaryUnmarshaler = (*BloomFilter)(nil)
+
+func TestMarshalBinary(t *testing.T) {
+	f := NewEnhanced(1000, 4)
+	f.Add([]byte("abc"))
+	bb, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g BloomFilter
+	if err := g.UnmarshalBinary(bb); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Test([]byte("abc")) || !g.Compatible(f) || !bytes.Equal(g.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+	if err := g.UnmarshalBinary(f.ToBytes()); err != ErrInvalidFormat {
+		t.Fail()
+	}
+	if err := g.UnmarshalBinary(bb[:len(bb)-8]); err != ErrInvalidFormat {
+		t.Fail()
+	}
+}
+
+// record holds a BloomFilter by value. go vet's copylocks check would reject
+// copying it if the filter held its lock by value.
+type record struct {
+	Name   string
+	Filter BloomFilter
+}
+
+func TestMarshalBinaryByValue(t *testing.T) {
+	r := record{Name: "users", Filter: *New(1000, 4)}
+	r.Filter.Add([]byte("abc"))
+	copied := r
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&copied); err != nil {
+		t.Fatal(err)
+	}
+	var decoded record
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Name != "users" || !decoded.Filter.Test([]byte("abc")) || decoded.Filter.Test([]byte("def")) {
+		t.Fail()
+	}
+}
+
+func TestMarshalBinarySeed(t *testing.T) {
+	f := NewWithOptions(1000, 4, WithSeed(42))
+	for i := 0; i < 100; i++ {
+		f.AddInt(i)
+	}
+	bb, _ := f.MarshalBinary()
+	var g BloomFilter
+	if err := g.UnmarshalBinary(bb); err != nil || !g.Compatible(f) {
+		t.Fatal(err)
+	}
+	mf, err := NewFromMmap(bb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := ReadWithMeta(bytes.NewReader(bb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if !g.TestInt(i) || !mf.TestInt(i) || !r.TestInt(i) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+}
+
+func TestMarshalBinaryHasher(t *testing.T) {
+	f := NewWithOptions(1000, 4, WithHasher(xorHasher(7)))
+	f.Add([]byte("abc"))
+	bb, _ := f.MarshalBinary()
+	if _, err := NewFromMmap(bb); err != ErrHasherNotStored {
+		t.Log(err)
+		t.Fail()
+	}
+	var g BloomFilter
+	if err := g.UnmarshalBinary(bb); err != ErrHasherNotStored {
+		t.Log(err)
+		t.Fail()
+	}
+	h := NewWithOptions(0, 1, WithHasher(xorHasher(7)))
+	if err := h.UnmarshalBinary(bb); err != nil || !h.Test([]byte("abc")) || !h.Compatible(f) {
+		t.Log(err)
+		t.Fail()
+	}
+	if err := h.UnmarshalBinary(New(1000, 4).ToAlignedBytes()); err != ErrHashMismatch {
+		t.Log(err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,29 @@
+package bloomfilter
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// WriteBitmap writes the bits of the bloom filter to w as a monochrome PNG
+// image of the given width, with bit i at column i%width of row i/width and
+// set bits black, as a visual aid for spotting clustering or hash skew.
+// If width does not divide m the last row is padded with white.
+func (bf *BloomFilter) WriteBitmap(w io.Writer, width int) error {
+	if width <= 0 {
+		return errors.New("bloomfilter: width must be positive")
+	}
+	var buckets = bf.snapshot()
+	var m = len(buckets) * 32
+	var img = image.NewPaletted(image.Rect(0, 0, width, (m+width-1)/width),
+		color.Palette{color.White, color.Black})
+	for i := 0; i < m; i++ {
+		if buckets[i/32]&(1<<(uint(i)%32)) != 0 {
+			img.SetColorIndex(i%width, i/width, 1)
+		}
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,45 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestWriteBitmap(t *testing.T) {
+	f := New(1024, 3)
+	for i := 0; i < 100; i++ {
+		f.Add([]byte(fmt.Sprint(i)))
+	}
+	for _, width := range []int{32, 100} {
+		var buf bytes.Buffer
+		if err := f.WriteBitmap(&buf, width); err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != width || b.Dy() != (1024+width-1)/width {
+			t.Log(width, b)
+			t.Fail()
+		}
+		black := 0
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < width; x++ {
+				if color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y == 0 {
+					black++
+				}
+			}
+		}
+		if black != int(f.PopCount()) {
+			t.Log(width, black, f.PopCount())
+			t.Fail()
+		}
+	}
+	if f.WriteBitmap(&bytes.Buffer{}, 0) == nil {
+		t.Fail()
+	}
+}
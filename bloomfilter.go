@@ -2,19 +2,51 @@ package bloomfilter
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"math"
 	"sync"
+	"sync/atomic"
 )
 
+// BloomFilter is a bloom filter safe for concurrent use. Its lock is held by
+// pointer, so a BloomFilter copied by value, for example as a field of a
+// struct, shares its lock and buckets with the original; use Clone for an
+// independent copy.
 type BloomFilter struct {
-	m       uint32
-	k       int
-	buckets []uint32
-	lock    sync.RWMutex
+	// adds, tests, positives and duplicates are accessed atomically and must
+	// stay first in the struct for 64-bit alignment on 32-bit platforms.
+	adds       uint64
+	tests      uint64
+	positives  uint64
+	duplicates uint64
+	stats      bool
+	hashing    hashing
+	sink       func([]byte)
+	// overrides maps keys marked with MarkExact to whether they were added
+	overrides map[string]bool
+	// count is the number of adds that set a new bit, kept if exactCount is set
+	exactCount bool
+	count      uint64
+	order      binary.ByteOrder
+	m          uint32
+	k          int
+	buckets    []uint32
+	lock       locker
+}
+
+// locker is the lock guarding the buckets, normally a *sync.RWMutex
+type locker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
 }
 
 // New creates a new bloom filter. m should specify the number of bits.
-// m is rounded up to the nearest multiple of 32.
+// m is rounded up to the nearest multiple of 32, so New(10, k) has 32 bits;
+// use M to get the actual size or NewExact to avoid rounding.
 // k specifies the number of hashing functions.
 func New(m, k int) *BloomFilter {
 	var n = uint32(math.Ceil(float64(m) / 32))
@@ -22,7 +54,67 @@ func New(m, k int) *BloomFilter {
 		m:       n * 32,
 		k:       k,
 		buckets: make([]uint32, n),
+		lock:    &sync.RWMutex{},
+	}
+}
+
+// NewExact creates a new bloom filter like New, but returns an error instead of
+// rounding if m is not a positive multiple of 32.
+func NewExact(m, k int) (*BloomFilter, error) {
+	if m <= 0 || m%32 != 0 {
+		return nil, errors.New("bloomfilter: m must be a positive multiple of 32")
+	}
+	return New(m, k), nil
+}
+
+// M returns the number of bits in the bloom filter, after rounding up to a multiple of 32
+func (bf *BloomFilter) M() int {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return int(bf.m)
+}
+
+// params returns m, k and the hashing scheme, read under one read lock
+func (bf *BloomFilter) params() (m uint32, k int, h hashing) {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return bf.m, bf.k, bf.hashing
+}
+
+// K returns the number of hashing functions
+func (bf *BloomFilter) K() int {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return bf.k
+}
+
+// Validate checks that the bloom filter is internally consistent: m matches the
+// number of buckets, k is positive and the hash configuration is not degenerate.
+// A filter that fails Validate may panic in Test.
+func (bf *BloomFilter) Validate() error {
+	if bf.lock == nil {
+		return fmt.Errorf("%w: filter was not created by a constructor", ErrInvalidFormat)
+	}
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	if uint64(bf.m) != uint64(len(bf.buckets))*32 {
+		return fmt.Errorf("%w: m is %d but buckets hold %d bits", ErrInvalidFormat, bf.m, len(bf.buckets)*32)
+	}
+	if bf.k < 1 {
+		return fmt.Errorf("%w: k is %d", ErrInvalidFormat, bf.k)
+	}
+	return bf.hashing.check()
+}
+
+// NewPow2 creates a new bloom filter like New with m = 1<<bits, at least 32.
+// Bit indexes in a power-of-two filter are reduced with a mask instead of a
+// division, which is faster and, since 2^32 is a multiple of m, exactly as
+// uniform. bits must be at most 31.
+func NewPow2(bits uint, k int) *BloomFilter {
+	if bits > 31 {
+		panic(fmt.Sprintf("bloomfilter: 1<<%d bits is too large", bits))
 	}
+	return New(1<<bits, k)
 }
 
 // NewFromBytes creates a new bloom filter from a byte slice.
@@ -37,6 +129,7 @@ func NewFromBytes(bb []byte, k int) *BloomFilter {
 		m:       uint32(len(ii) * 32),
 		k:       k,
 		buckets: ii,
+		lock:    &sync.RWMutex{},
 	}
 }
 
@@ -51,26 +144,265 @@ func EstimateParameters(n int, p float64) (m int, k int) {
 	return
 }
 
+// NewEnhanced creates a new bloom filter like New that derives its bit indexes
+// using enhanced double hashing. This spreads indexes better than the default
+// scheme when k is large relative to m, at the cost of compatibility with
+// bloomfilter.js. Filters loaded with NewFromBytes use the default scheme.
+func NewEnhanced(m, k int) *BloomFilter {
+	var bf = New(m, k)
+	bf.hashing.enhanced = true
+	return bf
+}
+
 func (bf *BloomFilter) locations(v []byte) []uint32 {
-	var r = make([]uint32, bf.k)
-	var a = fnv_1a(v, 0)
-	var b = fnv_1a(v, 1576284489)
-	var x = a % uint32(bf.m)
+	return bf.hashing.locations(v, bf.m, bf.k)
+}
+
+// Hashes returns the k bit indexes of v in the bloom filter
+func (bf *BloomFilter) Hashes(v []byte) []uint32 {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return bf.locations(v)
+}
+
+// MembershipProof returns the k bit indexes of v and whether each is set, so a
+// verifier with the same m, k and hashing scheme can confirm the result of Test:
+// v tests positive exactly when every bit is set.
+func (bf *BloomFilter) MembershipProof(v []byte) (indices []uint32, set []bool) {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	indices = bf.locations(v)
+	set = make([]bool, len(indices))
+	for i, l := range indices {
+		set[i] = bf.buckets[l/32]&(1<<(l%32)) != 0
+	}
+	return indices, set
+}
+
+func (bf *BloomFilter) hashLocations(a, b uint32) []uint32 {
+	return bf.hashing.hashLocations(a, b, bf.m, bf.k)
+}
+
+// hashing selects how bit indexes are derived from a value.
+// The zero value is the scheme used by bloomfilter.js.
+type hashing struct {
+	enhanced bool
+	summary  bool
+	seed     uint64
+	hasher   Hasher
+}
+
+func (h hashing) locations(v []byte, m uint32, k int) []uint32 {
+	var a, b = h.baseHashes(v)
+	return h.hashLocations(a, b, m, k)
+}
+
+// seeds returns the fnv seeds of the two base hashes
+func (h hashing) seeds() (uint32, uint32) {
+	return uint32(h.seed), 1576284489 ^ uint32(h.seed>>32)
+}
+
+// check returns ErrDegenerateHash if the two base hashes are always equal,
+// which collapses double hashing to a single stride for every key.
+func (h hashing) check() error {
+	if h.hasher == nil {
+		if a, b := h.seeds(); a == b {
+			return ErrDegenerateHash
+		}
+		return nil
+	}
+	for i := 0; i < 16; i++ {
+		if a, b := h.baseHashes([]byte{byte(i)}); a != b {
+			return nil
+		}
+	}
+	return ErrDegenerateHash
+}
+
+// equal reports whether h and o derive the same bit indexes. Hashers are
+// compared with sameHasher, so a non-comparable hasher does not panic.
+func (h hashing) equal(o hashing) bool {
+	return h.enhanced == o.enhanced && h.summary == o.summary && h.seed == o.seed &&
+		sameHasher(h.hasher, o.hasher)
+}
+
+// baseHashes returns the two hashes of v from which bit indexes are derived
+func (h hashing) baseHashes(v []byte) (uint32, uint32) {
+	if h.hasher != nil {
+		var x = h.hasher.Hash(v)
+		return uint32(x >> 32), uint32(x)
+	}
+	var seedA, seedB = h.seeds()
+	return fnv_1a_pair(v, seedA, seedB)
+}
+
+func (h hashing) hashLocations(a, b uint32, m uint32, k int) []uint32 {
+	var r = make([]uint32, h.size(k))
+	h.fill(r, a, b, m)
+	return r
+}
+
+// size returns the number of bits set per key, which is k plus the summary bit if any
+func (h hashing) size(k int) int {
+	if h.summary {
+		return k + 1
+	}
+	return k
+}
+
+// fill fills r, of length h.size(k), with the bit indexes derived from base hashes a and b.
+// A summary bit is placed second, in the same word as the first index.
+func (h hashing) fill(r []uint32, a, b uint32, m uint32) {
+	var rest = r
+	if h.summary {
+		rest = r[1:]
+	}
+	if h.enhanced {
+		fillEnhancedLocations(rest, a, b, m)
+	} else {
+		fillLocations(rest, a, b, m)
+	}
+	if h.summary && len(rest) > 0 {
+		var first = rest[0]
+		r[0] = first
+		r[1] = first&^31 | (first+1+b%31)%32
+	}
+}
+
+// HashScheme returns a description of exactly how the bloom filter derives
+// bit indexes from a value, including any configured seed or hasher.
+func (bf *BloomFilter) HashScheme() string {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return fmt.Sprintf("%s, m=%d, k=%d", bf.hashing, bf.m, bf.k)
+}
+
+func (h hashing) String() string {
+	var name, base string
+	if h.hasher != nil {
+		name = fmt.Sprintf("%T", h.hasher)
+		base = "a=Hash(v)>>32, b=Hash(v)&0xffffffff"
+	} else {
+		name = "FNV-1a"
+		var seedA, seedB = h.seeds()
+		base = fmt.Sprintf("a=fnv(v,%d), b=fnv(v,%d)", seedA, seedB)
+	}
+	var scheme = fmt.Sprintf("%s double hashing: h_i = (a + i*b) mod m, %s", name, base)
+	if h.enhanced {
+		scheme = fmt.Sprintf("%s enhanced double hashing: h_i = (a + i*b + i*(i-1)/2) mod m, %s", name, base)
+	}
+	if h.summary {
+		scheme += ", summary bit: h_0 - h_0%32 + (h_0 + 1 + b%31)%32"
+	}
+	return scheme
+}
+
+// locations returns the k bit indexes for v in a filter of m bits.
+func locations(v []byte, m uint32, k int) []uint32 {
+	return hashing{}.locations(v, m, k)
+}
+
+// hashLocations returns the k bit indexes derived from base hashes a and b in a filter of m bits.
+func hashLocations(a, b uint32, m uint32, k int) []uint32 {
+	var r = make([]uint32, k)
+	fillLocations(r, a, b, m)
+	return r
+}
+
+// fillLocations fills r with bit indexes derived from base hashes a and b.
+// A power-of-two m is reduced with a mask, which gives the same indexes.
+func fillLocations(r []uint32, a, b uint32, m uint32) {
+	if m&(m-1) == 0 {
+		var mask = m - 1
+		var x = a & mask
+		for i := range r {
+			r[i] = x
+			x = (x + b) & mask
+		}
+		return
+	}
+	var x = a % m
 	for i := range r {
 		r[i] = x
-		x = (x + b) % bf.m
+		x = (x + b) % m
 	}
-	return r
 }
 
-// Add adds a byte array to the bloom filter
+// enhancedLocations returns the k bit indexes for v in a filter of m bits using
+// enhanced double hashing (Kirsch & Mitzenmacher), which adds a quadratic term
+// to the stride so indexes do not cycle when the stride shares factors with m.
+func enhancedLocations(v []byte, m uint32, k int) []uint32 {
+	return hashing{enhanced: true}.locations(v, m, k)
+}
+
+// fillEnhancedLocations is fillLocations using enhanced double hashing.
+func fillEnhancedLocations(r []uint32, a, b uint32, m uint32) {
+	if m&(m-1) == 0 {
+		var mask = m - 1
+		var x = a & mask
+		var y = b & mask
+		for i := range r {
+			r[i] = x
+			x = (x + y) & mask
+			y = (y + 1) & mask
+		}
+		return
+	}
+	var x = a % m
+	var y = b % m
+	for i := range r {
+		r[i] = x
+		x = (x + y) % m
+		y = (y + 1) % m
+	}
+}
+
+// Add adds a byte array to the bloom filter. Add does not retain v, so the
+// caller may reuse it once Add returns; a key sink is passed its own copy.
 func (bf *BloomFilter) Add(v []byte) {
 	bf.lock.Lock()
 	defer bf.lock.Unlock()
-	var loc = bf.locations(v)
+	if bf.stats {
+		atomic.AddUint64(&bf.adds, 1)
+	}
+	bf.add(v)
+}
+
+// AddRetained adds a byte array to the bloom filter like Add, for callers that
+// want it explicit that any key sink is passed a stable copy of v, which the
+// caller's later writes to v cannot change. Add already behaves this way.
+func (bf *BloomFilter) AddRetained(v []byte) {
+	bf.Add(v)
+}
+
+// add is Add without locking or stats. It reports whether adding v set at
+// least one new bit. Every path that adds a key goes through add, so keys
+// marked with MarkExact are recorded.
+func (bf *BloomFilter) add(v []byte) bool {
+	if _, ok := bf.overrides[string(v)]; ok {
+		bf.overrides[string(v)] = true
+	}
+	bf.emit(v)
+	return bf.set(bf.locations(v))
+}
+
+// set sets the bits at loc and reports whether any of them was not already set
+func (bf *BloomFilter) set(loc []uint32) bool {
+	var fresh = false
 	for _, l := range loc {
-		bf.buckets[l/32] |= 1 << (l % 32)
+		var bit = uint32(1) << (l % 32)
+		if bf.buckets[l/32]&bit == 0 {
+			bf.buckets[l/32] |= bit
+			fresh = true
+		}
 	}
+	if fresh && bf.exactCount {
+		bf.count++
+	}
+	if !fresh && bf.stats {
+		atomic.AddUint64(&bf.duplicates, 1)
+	}
+	return fresh
 }
 
 // AddInt adds an int to the bloom filter
@@ -84,6 +416,36 @@ func (bf *BloomFilter) AddInt(v int) {
 func (bf *BloomFilter) Test(v []byte) bool {
 	bf.lock.RLock()
 	defer bf.lock.RUnlock()
+	if bf.stats {
+		atomic.AddUint64(&bf.tests, 1)
+	}
+	if !bf.test(v) {
+		return false
+	}
+	if bf.stats {
+		atomic.AddUint64(&bf.positives, 1)
+	}
+	return true
+}
+
+// TestWithHooks evaluates a byte array like Test and then calls onMiss if it is
+// definitely not in the bloom filter, or onMaybe if it probably is. Either
+// hook may be nil. Hooks are called after the lock is released.
+func (bf *BloomFilter) TestWithHooks(v []byte, onMiss, onMaybe func()) bool {
+	var ok = bf.Test(v)
+	if ok && onMaybe != nil {
+		onMaybe()
+	} else if !ok && onMiss != nil {
+		onMiss()
+	}
+	return ok
+}
+
+// test is Test without locking or stats
+func (bf *BloomFilter) test(v []byte) bool {
+	if present, ok := bf.overrides[string(v)]; ok {
+		return present
+	}
 	var loc = bf.locations(v)
 	for _, l := range loc {
 		if (bf.buckets[l/32] & (1 << (l % 32))) == 0 {
@@ -102,19 +464,237 @@ func (bf *BloomFilter) TestInt(v int) bool {
 	return bf.Test(a)
 }
 
-// ToBytes returns the bloom filter as a byte slice
+// TestIntRange reports whether any int in [lo, hi] is (probably) in the bloom
+// filter, stopping at the first one that is. It returns false if lo > hi.
+func (bf *BloomFilter) TestIntRange(lo, hi int) bool {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var a = make([]byte, 4)
+	for v := lo; v <= hi; v++ {
+		binary.BigEndian.PutUint32(a, uint32(v))
+		var ok = bf.test(a)
+		if bf.stats {
+			atomic.AddUint64(&bf.tests, 1)
+			if ok {
+				atomic.AddUint64(&bf.positives, 1)
+			}
+		}
+		if ok || v == hi {
+			return ok
+		}
+	}
+	return false
+}
+
+// Reset removes all items from the bloom filter
+func (bf *BloomFilter) Reset() {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	for i := range bf.buckets {
+		bf.buckets[i] = 0
+	}
+	bf.count = 0
+	bf.resetOverrides()
+}
+
+// resetOverrides marks every key marked with MarkExact as not added
+func (bf *BloomFilter) resetOverrides() {
+	for key := range bf.overrides {
+		bf.overrides[key] = false
+	}
+}
+
+// ClearRange zeros the bits in [startBit, endBit), removing every item whose
+// bits all fall in the range and possibly introducing false negatives for items
+// with only some bits in it.
+func (bf *BloomFilter) ClearRange(startBit, endBit uint32) error {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if startBit > endBit || endBit > bf.m {
+		return errors.New("bloomfilter: range out of bounds")
+	}
+	for l := startBit; l < endBit; {
+		if l%32 == 0 && endBit-l >= 32 {
+			bf.buckets[l/32] = 0
+			l += 32
+			continue
+		}
+		bf.buckets[l/32] &^= 1 << (l % 32)
+		l++
+	}
+	return nil
+}
+
+// ResetWithSeed removes all items from the bloom filter and installs a new hash
+// seed in a single operation, so no item is ever hashed with a mix of seeds.
+func (bf *BloomFilter) ResetWithSeed(seed uint64) {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	for i := range bf.buckets {
+		bf.buckets[i] = 0
+	}
+	bf.count = 0
+	bf.resetOverrides()
+	bf.hashing.seed = seed
+}
+
+// SetK changes the number of hashing functions. Changing k on a populated
+// filter would invalidate it, so SetK returns ErrNotEmpty unless the filter is empty.
+func (bf *BloomFilter) SetK(k int) error {
+	if k < 1 {
+		return errors.New("bloomfilter: k must be positive")
+	}
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if popCount(bf.buckets) != 0 {
+		return ErrNotEmpty
+	}
+	bf.k = k
+	return nil
+}
+
+// AddFloat32 adds a float32 to the bloom filter. -0 is added as +0, since the
+// two compare equal; NaNs are added by bit pattern.
+func (bf *BloomFilter) AddFloat32(v float32) {
+	bf.Add(float32Bytes(v))
+}
+
+// TestFloat32 evaluates a float32 to determine whether it is (probably) in the bloom filter
+func (bf *BloomFilter) TestFloat32(v float32) bool {
+	return bf.Test(float32Bytes(v))
+}
+
+func float32Bytes(v float32) []byte {
+	if v == 0 {
+		v = 0
+	}
+	var a = make([]byte, 4)
+	binary.BigEndian.PutUint32(a, math.Float32bits(v))
+	return a
+}
+
+// Reserve resizes an empty bloom filter for n items at false positive rate p,
+// setting m and k with EstimateParameters and allocating the buckets up front.
+// It returns ErrNotEmpty if any bit is set. New(0, 1) gives a filter to Reserve
+// later. Reserve is meant for setup and should not race with other methods.
+func (bf *BloomFilter) Reserve(n int, p float64) error {
+	if n < 1 {
+		return errors.New("bloomfilter: n must be positive")
+	}
+	if !(p > 0 && p < 1) {
+		return errors.New("bloomfilter: p must be between 0 and 1")
+	}
+	var m, k = EstimateParameters(n, p)
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if popCount(bf.buckets) != 0 {
+		return ErrNotEmpty
+	}
+	bf.m = uint32(m)
+	bf.k = k
+	bf.buckets = make([]uint32, m/32)
+	return nil
+}
+
+// ToBytes returns the bloom filter as a byte slice.
+// The buckets are copied under the lock and encoded after it is released.
 func (bf *BloomFilter) ToBytes() []byte {
+	return bucketsToBytes(bf.snapshot(), bf.byteOrder())
+}
+
+// WriteTo writes the bloom filter to w in the same format as ToBytes.
+// The buckets are copied under the lock and written after it is released.
+func (bf *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(bucketsToBytes(bf.snapshot(), bf.byteOrder()))
+	return int64(n), err
+}
+
+// AppendBytes appends the bloom filter in the same format as ToBytes to dst and
+// returns the extended slice. It does not allocate if dst has enough capacity,
+// but holds the read lock while encoding.
+func (bf *BloomFilter) AppendBytes(dst []byte) []byte {
 	bf.lock.RLock()
 	defer bf.lock.RUnlock()
-	var bb = []byte{}
-	for _, bucket := range bf.buckets {
-		var a = make([]byte, 4)
-		binary.BigEndian.PutUint32(a, bucket)
-		bb = append(bb, a...)
+	var n = len(dst)
+	if cap(dst)-n < len(bf.buckets)*4 {
+		var grown = make([]byte, n, n+len(bf.buckets)*4)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:n+len(bf.buckets)*4]
+	var order = bf.byteOrder()
+	for i, bucket := range bf.buckets {
+		order.PutUint32(dst[n+i*4:], bucket)
 	}
+	return dst
+}
+
+// Buckets returns the bloom filter's backing slice of 32-bit buckets without
+// copying, bit l being bit l%32 of bucket l/32. It is read-only: writes
+// through it change the filter without its lock. Reading it while the filter
+// is written concurrently is a data race; use BucketsCopy in that case.
+func (bf *BloomFilter) Buckets() []uint32 {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return bf.buckets
+}
+
+// BucketsCopy returns a copy of the bloom filter's buckets, which is safe to
+// use while the filter is written.
+func (bf *BloomFilter) BucketsCopy() []uint32 {
+	return bf.snapshot()
+}
+
+// byteOrder returns the byte order of the buckets in ToBytes, big-endian by default
+func (bf *BloomFilter) byteOrder() binary.ByteOrder {
+	if bf.order == nil {
+		return binary.BigEndian
+	}
+	return bf.order
+}
+
+func bucketsToBytes(buckets []uint32, order binary.ByteOrder) []byte {
+	var bb = make([]byte, len(buckets)*4)
+	for i, bucket := range buckets {
+		order.PutUint32(bb[i*4:], bucket)
+	}
+	return bb
+}
+
+// snapshot returns a copy of the buckets taken under the read lock
+func (bf *BloomFilter) snapshot() []uint32 {
+	var bb = bf.lockedSnapshot()
+	bf.lock.RUnlock()
 	return bb
 }
 
+// lockedSnapshot returns a copy of the buckets with the read lock still held, so
+// other fields can be read consistently with it. The copy is allocated before
+// the lock is taken, so the lock is only held for a single copy().
+func (bf *BloomFilter) lockedSnapshot() []uint32 {
+	for {
+		bf.lock.RLock()
+		var n = len(bf.buckets)
+		bf.lock.RUnlock()
+		var bb = make([]uint32, n)
+		bf.lock.RLock()
+		if len(bf.buckets) == n {
+			copy(bb, bf.buckets)
+			return bb
+		}
+		bf.lock.RUnlock()
+	}
+}
+
+// emit passes a copy of v to the key sink, if one is set
+func (bf *BloomFilter) emit(v []byte) {
+	if bf.sink != nil {
+		var c = make([]byte, len(v))
+		copy(c, v)
+		bf.sink(c)
+	}
+}
+
 // Fowler/Noll/Vo hashing.
 // Nonstandard variation: this function optionally takes a seed value that is incorporated
 // into the offset basis. According to http://www.isthe.com/chongo/tech/comp/fnv/index.html
@@ -132,6 +712,18 @@ func fnv_1a(v []byte, seed int) uint32 {
 	return fnv_mix(a)
 }
 
+// fnv_1a_pair computes fnv_1a(v, seedA) and fnv_1a(v, seedB) in a single pass over v.
+// The high byte branch of fnv_1a is omitted because it never applies to a byte.
+func fnv_1a_pair(v []byte, seedA, seedB uint32) (uint32, uint32) {
+	var a = 2166136261 ^ seedA
+	var b = 2166136261 ^ seedB
+	for _, c := range v {
+		a = fnv_multiply(a ^ uint32(c))
+		b = fnv_multiply(b ^ uint32(c))
+	}
+	return fnv_mix(a), fnv_mix(b)
+}
+
 // a * 16777619 mod 2**32
 func fnv_multiply(a uint32) uint32 {
 	return a + (a << 1) + (a << 4) + (a << 7) + (a << 8) + (a << 24)
@@ -1,9 +1,13 @@
 package bloomfilter
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/binary"
-	// "fmt"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -148,3 +152,454 @@ func BenchmarkSeparateAdd(b *testing.B) {
 		f.Add(key)
 	}
 }
+
+func distinctRatio(locate func([]byte, uint32, int) []uint32, m uint32, k int) float64 {
+	var total, distinct = 0, 0
+	key := make([]byte, 4)
+	for i := 0; i < 1000; i++ {
+		binary.BigEndian.PutUint32(key, uint32(i))
+		seen := map[uint32]bool{}
+		for _, l := range locate(key, m, k) {
+			seen[l] = true
+		}
+		total += k
+		distinct += len(seen)
+	}
+	return float64(distinct) / float64(total)
+}
+
+func TestEnhancedDistinctIndexes(t *testing.T) {
+	var m uint32 = 256
+	k := 64
+	standard := distinctRatio(locations, m, k)
+	enhanced := distinctRatio(enhancedLocations, m, k)
+	t.Log(standard, enhanced)
+	if enhanced <= standard {
+		t.Fail()
+	}
+}
+
+func TestEnhanced(t *testing.T) {
+	f := NewEnhanced(1000, 4)
+	f.Add([]byte("abc"))
+	if !f.Test([]byte("abc")) || !f.Freeze().Test([]byte("abc")) {
+		t.Fail()
+	}
+	if f.Test([]byte("def")) {
+		t.Fail()
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	var buf bytes.Buffer
+	n, err := f.WriteTo(&buf)
+	if err != nil || n != int64(len(f.ToBytes())) {
+		t.Log(n, err)
+		t.Fail()
+	}
+	if !bytes.Equal(buf.Bytes(), f.ToBytes()) {
+		t.Fail()
+	}
+}
+
+func TestConcurrentAddToBytes(t *testing.T) {
+	f := New(100000, 4)
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 10000; i++ {
+			f.AddInt(i)
+		}
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			f2 := NewFromBytes(f.ToBytes(), 4)
+			for i := 0; i < 10000; i++ {
+				if !f2.TestInt(i) {
+					t.Fatal(i)
+				}
+			}
+			return
+		default:
+			f.ToBytes()
+		}
+	}
+}
+
+// BenchmarkToBytesLockHold measures the time ToBytes holds the read lock
+func BenchmarkToBytesLockHold(b *testing.B) {
+	m, k := EstimateParameters(1000000, 1e-4)
+	f := New(m, k)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.snapshot()
+	}
+}
+
+// BenchmarkToBytesEncode measures the encoding ToBytes performs after releasing the lock
+func BenchmarkToBytesEncode(b *testing.B) {
+	m, k := EstimateParameters(1000000, 1e-4)
+	f := New(m, k)
+	buckets := f.snapshot()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bucketsToBytes(buckets, binary.BigEndian)
+	}
+}
+
+func TestSetK(t *testing.T) {
+	f := New(1000, 4)
+	if err := f.SetK(7); err != nil || f.k != 7 {
+		t.Fail()
+	}
+	if err := f.SetK(0); err == nil {
+		t.Fail()
+	}
+	f.Add([]byte("abc"))
+	if err := f.SetK(4); err != ErrNotEmpty || f.k != 7 {
+		t.Fail()
+	}
+}
+
+func TestFnvPair(t *testing.T) {
+	for _, v := range []string{"", "abc", "Ā", jabberwocky} {
+		a, b := fnv_1a_pair([]byte(v), 0, 1576284489)
+		if a != fnv_1a([]byte(v), 0) || b != fnv_1a([]byte(v), 1576284489) {
+			t.Log(v)
+			t.Fail()
+		}
+	}
+}
+
+func BenchmarkLocationsLargeValue(b *testing.B) {
+	f := New(1000000, 7)
+	v := make([]byte, 4096)
+	b.SetBytes(int64(len(v)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.locations(v)
+	}
+}
+
+func BenchmarkFnvSeparateLargeValue(b *testing.B) {
+	v := make([]byte, 4096)
+	b.SetBytes(int64(len(v)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fnv_1a(v, 0)
+		fnv_1a(v, 1576284489)
+	}
+}
+
+func TestReset(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	f.Reset()
+	if f.PopCount() != 0 || f.Test([]byte("abc")) {
+		t.Fail()
+	}
+}
+
+func TestResetWithSeed(t *testing.T) {
+	f := NewWithOptions(1000, 4, WithSeed(1))
+	before := f.locations([]byte("abc"))
+	f.Add([]byte("abc"))
+	f.ResetWithSeed(2)
+	if f.PopCount() != 0 || f.Test([]byte("abc")) {
+		t.Fail()
+	}
+	if reflect.DeepEqual(before, f.locations([]byte("abc"))) {
+		t.Fail()
+	}
+	f.Add([]byte("abc"))
+	if !f.Test([]byte("abc")) {
+		t.Fail()
+	}
+}
+
+func TestM(t *testing.T) {
+	for m, expected := range map[int]int{1: 32, 10: 32, 32: 32, 33: 64, 1000: 1024} {
+		f := New(m, 4)
+		if f.M() != expected || f.K() != 4 {
+			t.Log(m, f.M())
+			t.Fail()
+		}
+	}
+}
+
+func TestNewExact(t *testing.T) {
+	f, err := NewExact(64, 4)
+	if err != nil || f.M() != 64 {
+		t.Fail()
+	}
+	for _, m := range []int{0, -32, 10, 1000} {
+		if _, err := NewExact(m, 4); err == nil {
+			t.Log(m)
+			t.Fail()
+		}
+	}
+}
+
+func TestAppendBytes(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	bb := f.AppendBytes([]byte("prefix"))
+	if string(bb[:6]) != "prefix" || !bytes.Equal(bb[6:], f.ToBytes()) {
+		t.Fail()
+	}
+	if !bytes.Equal(f.AppendBytes(nil), f.ToBytes()) {
+		t.Fail()
+	}
+}
+
+func BenchmarkAppendBytes(b *testing.B) {
+	m, k := EstimateParameters(10000, 1e-4)
+	f := New(m, k)
+	buf := make([]byte, 0, f.SizeBytes())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = f.AppendBytes(buf[:0])
+	}
+}
+
+func TestClearRange(t *testing.T) {
+	f := New(1024, 4)
+	var inside, outside []byte
+	for i := 0; inside == nil || outside == nil; i++ {
+		v := []byte(fmt.Sprint(i))
+		low, high := true, true
+		for _, l := range f.locations(v) {
+			low = low && l >= 100 && l < 500
+			high = high && l >= 500
+		}
+		if low && inside == nil {
+			inside = v
+		}
+		if high && outside == nil {
+			outside = v
+		}
+	}
+	f.Add(inside)
+	f.Add(outside)
+	if err := f.ClearRange(100, 500); err != nil {
+		t.Fatal(err)
+	}
+	if f.Test(inside) || !f.Test(outside) {
+		t.Fail()
+	}
+	if err := f.ClearRange(0, 1024); err != nil || f.PopCount() != 0 {
+		t.Fail()
+	}
+	if f.ClearRange(10, 5) == nil || f.ClearRange(0, 1025) == nil {
+		t.Fail()
+	}
+}
+
+func TestHashScheme(t *testing.T) {
+	for _, c := range []struct {
+		f        *BloomFilter
+		expected string
+	}{
+		{New(1000, 4),
+			"FNV-1a double hashing: h_i = (a + i*b) mod m, a=fnv(v,0), b=fnv(v,1576284489), m=1024, k=4"},
+		{NewWithOptions(32, 2, WithSeed(1<<32|7)),
+			"FNV-1a double hashing: h_i = (a + i*b) mod m, a=fnv(v,7), b=fnv(v,1576284488), m=32, k=2"},
+		{NewEnhanced(64, 3),
+			"FNV-1a enhanced double hashing: h_i = (a + i*b + i*(i-1)/2) mod m, a=fnv(v,0), b=fnv(v,1576284489), m=64, k=3"},
+		{NewWithOptions(32, 1, WithHasher(xorHasher(0))),
+			"bloomfilter.xorHasher double hashing: h_i = (a + i*b) mod m, a=Hash(v)>>32, b=Hash(v)&0xffffffff, m=32, k=1"},
+	} {
+		if actual := c.f.HashScheme(); actual != c.expected {
+			t.Log(actual)
+			t.Fail()
+		}
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	f := New(1000, 4)
+	v := float32(1.5)
+	next := math.Nextafter32(v, 2)
+	f.AddFloat32(v)
+	if !f.TestFloat32(v) || f.TestFloat32(next) {
+		t.Fail()
+	}
+	if reflect.DeepEqual(f.locations(float32Bytes(v)), f.locations(float32Bytes(next))) {
+		t.Fail()
+	}
+	f.AddFloat32(float32(math.Copysign(0, -1)))
+	if !f.TestFloat32(0) {
+		t.Fail()
+	}
+}
+
+func TestMembershipProof(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	for i := 0; i < 100; i++ {
+		v := []byte(fmt.Sprint(i))
+		indices, set := f.MembershipProof(v)
+		if !reflect.DeepEqual(indices, locations(v, 1024, 4)) || len(set) != 4 {
+			t.Fatal(i)
+		}
+		all := true
+		for _, s := range set {
+			all = all && s
+		}
+		if all != f.Test(v) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+	_, set := f.MembershipProof([]byte("abc"))
+	if !reflect.DeepEqual(set, []bool{true, true, true, true}) {
+		t.Fail()
+	}
+}
+
+func TestReserve(t *testing.T) {
+	f := New(0, 1)
+	if err := f.Reserve(10000, 1e-6); err != nil {
+		t.Fatal(err)
+	}
+	if f.M() != 287552 || f.K() != 20 || f.SizeBytes() != 287552/8 {
+		t.Log(f.M(), f.K())
+		t.Fail()
+	}
+	f.Add([]byte("abc"))
+	if !f.Test([]byte("abc")) {
+		t.Fail()
+	}
+	if err := f.Reserve(100, 1e-2); err != ErrNotEmpty || f.M() != 287552 {
+		t.Fail()
+	}
+	if f.Reserve(0, 1e-2) == nil || f.Reserve(100, 1) == nil {
+		t.Fail()
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f := New(1000, 4)
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewFromBytes(f.ToBytes(), 4).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	f.m += 32
+	if err := f.Validate(); !errors.Is(err, ErrInvalidFormat) {
+		t.Log(err)
+		t.Fail()
+	}
+	if err := NewFromBytes(f.ToBytes(), 0).Validate(); !errors.Is(err, ErrInvalidFormat) {
+		t.Log(err)
+		t.Fail()
+	}
+	if err := NewWithOptions(1000, 4, WithSeed(1576284489)).Validate(); err != ErrDegenerateHash {
+		t.Log(err)
+		t.Fail()
+	}
+	if err := new(BloomFilter).Validate(); !errors.Is(err, ErrInvalidFormat) {
+		t.Log(err)
+		t.Fail()
+	}
+}
+
+func TestTestIntRange(t *testing.T) {
+	f := New(10000, 4)
+	f.AddInt(1005)
+	if !f.TestIntRange(1000, 1010) || !f.TestIntRange(1005, 1005) {
+		t.Fail()
+	}
+	if f.TestIntRange(1006, 1010) || f.TestIntRange(1010, 1000) {
+		t.Fail()
+	}
+	f.AddInt(math.MaxInt32)
+	if !f.TestIntRange(math.MaxInt32-2, math.MaxInt32) {
+		t.Fail()
+	}
+}
+
+func TestNewPow2(t *testing.T) {
+	f := NewPow2(10, 4)
+	if f.M() != 1024 || NewPow2(2, 4).M() != 32 {
+		t.Fail()
+	}
+	// the mask must give the same indexes as the general modulo
+	for _, v := range []string{"", "abc", jabberwocky} {
+		a, b := hashing{}.baseHashes([]byte(v))
+		for _, m := range []uint32{32, 1024, 1 << 31} {
+			mask := make([]uint32, 7)
+			fillLocations(mask, a, b, m)
+			for i, l := range mask {
+				if l != (a+uint32(i)*b)%m {
+					t.Log(v, m, i)
+					t.Fail()
+				}
+			}
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+	NewPow2(32, 4)
+}
+
+func benchmarkTestM(b *testing.B, m int) {
+	f := New(m, 7)
+	v := []byte("abc")
+	f.Add(v)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Test(v)
+	}
+}
+
+func BenchmarkTestModulo(b *testing.B) {
+	benchmarkTestM(b, 1<<20+32)
+}
+
+func BenchmarkTestMask(b *testing.B) {
+	benchmarkTestM(b, 1<<20)
+}
+
+func TestBuckets(t *testing.T) {
+	f := New(1000, 4)
+	c := f.BucketsCopy()
+	f.Buckets()[0] = 0xffffffff
+	if f.PopCount() != 32 {
+		t.Log("write through Buckets not visible")
+		t.Fail()
+	}
+	if c[0] != 0 {
+		t.Log("BucketsCopy shares the backing slice")
+		t.Fail()
+	}
+	c[1] = 1
+	if f.Buckets()[1] != 0 {
+		t.Fail()
+	}
+}
+
+func TestTestWithHooks(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	var misses, maybes int
+	onMiss := func() { misses++ }
+	onMaybe := func() { maybes++ }
+	if !f.TestWithHooks([]byte("abc"), onMiss, onMaybe) || misses != 0 || maybes != 1 {
+		t.Fail()
+	}
+	if f.TestWithHooks([]byte("def"), onMiss, onMaybe) || misses != 1 || maybes != 1 {
+		t.Fail()
+	}
+	if !f.TestWithHooks([]byte("abc"), nil, nil) || f.TestWithHooks([]byte("def"), nil, nil) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,27 @@
+// Package bloomtest provides helpers for checking bloom filters against exact
+// sets in tests.
+package bloomtest
+
+// Tester is implemented by filters with a Test([]byte) bool method, such as
+// BloomFilter, CountingBloomFilter, FrozenFilter and MmapFilter in package bloomfilter.
+type Tester interface {
+	Test(v []byte) bool
+}
+
+// Validate tests f against ground truth. present holds items that were added to
+// f and absent holds items that were not. It returns the number of present
+// items that test negative, which must be 0 for a correct filter, and the
+// number of absent items that test positive.
+func Validate(f Tester, present [][]byte, absent [][]byte) (falseNegatives int, falsePositives int) {
+	for _, v := range present {
+		if !f.Test(v) {
+			falseNegatives++
+		}
+	}
+	for _, v := range absent {
+		if f.Test(v) {
+			falsePositives++
+		}
+	}
+	return falseNegatives, falsePositives
+}
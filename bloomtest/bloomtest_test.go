@@ -0,0 +1,51 @@
+package bloomtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jda/bloomfilter"
+)
+
+type exactSet map[string]bool
+
+func (s exactSet) Test(v []byte) bool {
+	return s[string(v)]
+}
+
+func keys(prefix string, n int) [][]byte {
+	var kk = make([][]byte, n)
+	for i := range kk {
+		kk[i] = []byte(fmt.Sprintf("%s-%d", prefix, i))
+	}
+	return kk
+}
+
+func TestValidate(t *testing.T) {
+	present := keys("present", 1000)
+	absent := keys("absent", 10000)
+	m, k := bloomfilter.EstimateParameters(len(present), 1e-2)
+	f := bloomfilter.New(m, k)
+	f.AddBatch(present)
+	fn, fp := Validate(f, present, absent)
+	if fn != 0 {
+		t.Log(fn)
+		t.Fail()
+	}
+	if fp == 0 || fp > 300 {
+		t.Log(fp)
+		t.Fail()
+	}
+	if fn, fp := Validate(f.Freeze(), present, absent); fn != 0 || fp == 0 {
+		t.Fail()
+	}
+}
+
+func TestValidateCounts(t *testing.T) {
+	s := exactSet{"a": true, "x": true}
+	fn, fp := Validate(s, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, [][]byte{[]byte("x"), []byte("y")})
+	if fn != 2 || fp != 1 {
+		t.Log(fn, fp)
+		t.Fail()
+	}
+}
@@ -0,0 +1,140 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// CountingBloomFilter is a bloom filter that keeps a small counter per
+// position instead of a single bit, which makes Remove possible.
+//
+// Counters are packed into 32-bit words. Wider counters cost more memory
+// (m*bitsPerCounter bits in total) but saturate later: a 4-bit counter
+// tops out at 15, an 8-bit counter at 255 and a 16-bit counter at 65535.
+// A saturated counter is never incremented or decremented again, so it
+// can cause false positives but never false negatives.
+type CountingBloomFilter struct {
+	m        uint32
+	k        int
+	width    uint32
+	max      uint32
+	counters []uint32
+	lock     sync.RWMutex
+}
+
+// NewCounting creates a new counting bloom filter. m should specify the number
+// of counters and is rounded up to the nearest multiple of 32.
+// k specifies the number of hashing functions.
+// bitsPerCounter must be 4, 8 or 16.
+func NewCounting(m, k, bitsPerCounter int) *CountingBloomFilter {
+	switch bitsPerCounter {
+	case 4, 8, 16:
+	default:
+		panic(fmt.Sprintf("bloomfilter: unsupported counter width %d", bitsPerCounter))
+	}
+	var n = uint32(math.Ceil(float64(m) / 32))
+	var width = uint32(bitsPerCounter)
+	var perWord = 32 / width
+	return &CountingBloomFilter{
+		m:        n * 32,
+		k:        k,
+		width:    width,
+		max:      1<<width - 1,
+		counters: make([]uint32, n*32/perWord),
+	}
+}
+
+func (cf *CountingBloomFilter) get(l uint32) uint32 {
+	var perWord = 32 / cf.width
+	var shift = (l % perWord) * cf.width
+	return (cf.counters[l/perWord] >> shift) & cf.max
+}
+
+func (cf *CountingBloomFilter) set(l, c uint32) {
+	var perWord = 32 / cf.width
+	var shift = (l % perWord) * cf.width
+	var w = &cf.counters[l/perWord]
+	*w = *w&^(cf.max<<shift) | c<<shift
+}
+
+// Add adds a byte array to the counting bloom filter. Add does not retain v.
+func (cf *CountingBloomFilter) Add(v []byte) {
+	cf.lock.Lock()
+	defer cf.lock.Unlock()
+	for _, l := range locations(v, cf.m, cf.k) {
+		if c := cf.get(l); c < cf.max {
+			cf.set(l, c+1)
+		}
+	}
+}
+
+// Remove removes a byte array from the counting bloom filter.
+// Removing a value that was never added may introduce false negatives.
+func (cf *CountingBloomFilter) Remove(v []byte) {
+	cf.lock.Lock()
+	defer cf.lock.Unlock()
+	for _, l := range locations(v, cf.m, cf.k) {
+		if c := cf.get(l); c > 0 && c < cf.max {
+			cf.set(l, c-1)
+		}
+	}
+}
+
+// Test evaluates a byte array to determine whether it is (probably) in the counting bloom filter
+func (cf *CountingBloomFilter) Test(v []byte) bool {
+	cf.lock.RLock()
+	defer cf.lock.RUnlock()
+	for _, l := range locations(v, cf.m, cf.k) {
+		if cf.get(l) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ToStandard returns a standard bloom filter with a bit set wherever the
+// counting bloom filter has a non-zero counter.
+func (cf *CountingBloomFilter) ToStandard() *BloomFilter {
+	cf.lock.RLock()
+	defer cf.lock.RUnlock()
+	var bf = New(int(cf.m), cf.k)
+	for l := uint32(0); l < cf.m; l++ {
+		if cf.get(l) != 0 {
+			bf.buckets[l/32] |= 1 << (l % 32)
+		}
+	}
+	return bf
+}
+
+// CountingUnion returns a new counting bloom filter whose counters are the sums
+// of the corresponding counters of others, clamped at the counter maximum, so
+// values added to any input can be removed from the result. All inputs must
+// have the same m, k and counter width. Each input is read under its own read lock.
+func CountingUnion(others ...*CountingBloomFilter) (*CountingBloomFilter, error) {
+	if len(others) == 0 {
+		return nil, ErrEmpty
+	}
+	var first = others[0]
+	for _, other := range others[1:] {
+		switch {
+		case other.m != first.m || other.width != first.width:
+			return nil, ErrSizeMismatch
+		case other.k != first.k:
+			return nil, ErrKMismatch
+		}
+	}
+	var u = NewCounting(int(first.m), first.k, int(first.width))
+	for _, other := range others {
+		other.lock.RLock()
+		for l := uint32(0); l < u.m; l++ {
+			if c := u.get(l) + other.get(l); c < u.max {
+				u.set(l, c)
+			} else {
+				u.set(l, u.max)
+			}
+		}
+		other.lock.RUnlock()
+	}
+	return u, nil
+}
@@ -0,0 +1,132 @@
+package bloomfilter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCountingBasic(t *testing.T) {
+	for _, w := range []int{4, 8, 16} {
+		f := NewCounting(1000, 4, w)
+		n1 := []byte("Bess")
+		n2 := []byte("Jane")
+		f.Add(n1)
+		if !f.Test(n1) {
+			t.Log(w)
+			t.Fail()
+		}
+		if f.Test(n2) {
+			t.Log(w)
+			t.Fail()
+		}
+		f.Remove(n1)
+		if f.Test(n1) {
+			t.Log(w)
+			t.Fail()
+		}
+	}
+}
+
+func TestCountingWidth(t *testing.T) {
+	n1 := []byte("Bess")
+	f4 := NewCounting(1000, 4, 4)
+	f8 := NewCounting(1000, 4, 8)
+	for i := 0; i < 200; i++ {
+		f4.Add(n1)
+		f8.Add(n1)
+	}
+	for i := 0; i < 200; i++ {
+		f4.Remove(n1)
+		f8.Remove(n1)
+	}
+	if f8.Test(n1) {
+		t.Log("8-bit counter did not return to zero")
+		t.Fail()
+	}
+	if !f4.Test(n1) {
+		t.Log("4-bit counter did not saturate")
+		t.Fail()
+	}
+}
+
+func TestCountingInvalidWidth(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+	NewCounting(1000, 4, 3)
+}
+
+func TestCountingToStandard(t *testing.T) {
+	f := NewCounting(1000, 4, 8)
+	for i := 0; i < 100; i++ {
+		f.Add([]byte{byte(i)})
+	}
+	for i := 0; i < 50; i++ {
+		f.Remove([]byte{byte(i)})
+	}
+	s := f.ToStandard()
+	for i := 0; i < 256; i++ {
+		if f.Test([]byte{byte(i)}) != s.Test([]byte{byte(i)}) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+}
+
+func TestCountingUnion(t *testing.T) {
+	a := NewCounting(1000, 4, 8)
+	b := NewCounting(1000, 4, 8)
+	n1 := []byte("Bess")
+	n2 := []byte("Jane")
+	a.Add(n1)
+	a.Add(n2)
+	b.Add(n1)
+	u, err := CountingUnion(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Remove(n1)
+	if !u.Test(n1) || !u.Test(n2) {
+		t.Log("value removed before its count reached zero")
+		t.Fail()
+	}
+	u.Remove(n1)
+	u.Remove(n2)
+	if u.Test(n1) || u.Test(n2) {
+		t.Log("summed counts did not return to zero")
+		t.Fail()
+	}
+	if !a.Test(n1) {
+		t.Fail()
+	}
+}
+
+func TestCountingUnionSaturates(t *testing.T) {
+	a := NewCounting(1000, 4, 4)
+	n1 := []byte("Bess")
+	for i := 0; i < 10; i++ {
+		a.Add(n1)
+	}
+	u, err := CountingUnion(a, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		u.Remove(n1)
+	}
+	if !u.Test(n1) {
+		t.Log("counter did not saturate")
+		t.Fail()
+	}
+}
+
+func TestCountingUnionIncompatible(t *testing.T) {
+	if _, err := CountingUnion(NewCounting(1000, 4, 8), NewCounting(1000, 4, 4)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+	if _, err := CountingUnion(); !errors.Is(err, ErrEmpty) {
+		t.Fail()
+	}
+}
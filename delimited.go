@@ -0,0 +1,41 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+// AddDelimited reads records from r, each an unsigned varint length followed by
+// that many bytes, and adds each record to the bloom filter until EOF.
+// It returns the number of records added. A truncated record is reported as
+// io.ErrUnexpectedEOF.
+func (bf *BloomFilter) AddDelimited(r io.Reader) (int, error) {
+	var br, ok = r.(io.ByteReader)
+	if !ok {
+		var b = bufio.NewReader(r)
+		br, r = b, b
+	}
+	var buf bytes.Buffer
+	var count = 0
+	for {
+		l, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return count, err
+		}
+		if l > math.MaxInt64 {
+			return count, errors.New("bloomfilter: record too long")
+		}
+		buf.Reset()
+		if err := readN(&buf, r, int64(l)); err != nil {
+			return count, err
+		}
+		bf.Add(buf.Bytes())
+		count++
+	}
+}
@@ -0,0 +1,54 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+func delimited(records ...string) []byte {
+	var bb []byte
+	a := make([]byte, binary.MaxVarintLen64)
+	for _, v := range records {
+		n := binary.PutUvarint(a, uint64(len(v)))
+		bb = append(bb, a[:n]...)
+		bb = append(bb, v...)
+	}
+	return bb
+}
+
+func TestAddDelimited(t *testing.T) {
+	records := []string{"abc", "", "def", strings.Repeat("x", 300)}
+	f := New(1000, 4)
+	n, err := f.AddDelimited(bytes.NewReader(delimited(records...)))
+	if err != nil || n != len(records) {
+		t.Log(n, err)
+		t.Fail()
+	}
+	for _, v := range records {
+		if !f.Test([]byte(v)) {
+			t.Log(v)
+			t.Fail()
+		}
+	}
+	if f.Test([]byte("ghi")) {
+		t.Fail()
+	}
+}
+
+func TestAddDelimitedTruncated(t *testing.T) {
+	bb := delimited("abc", "def")
+	f := New(1000, 4)
+	n, err := f.AddDelimited(bytes.NewReader(bb[:len(bb)-1]))
+	if n != 1 || err != io.ErrUnexpectedEOF {
+		t.Log(n, err)
+		t.Fail()
+	}
+	n, err = f.AddDelimited(bytes.NewReader([]byte{0x80}))
+	if n != 0 || err != io.ErrUnexpectedEOF {
+		t.Log(n, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,51 @@
+package bloomfilter
+
+// Classification is the result of DualFilter.Classify
+type Classification int
+
+const (
+	// Unknown means v was added to neither filter, or tests positive in both.
+	Unknown Classification = iota
+	// ProbablyPresent means v tests positive only in the present filter.
+	ProbablyPresent
+	// ProbablyAbsent means v tests positive only in the absent filter.
+	ProbablyAbsent
+)
+
+func (c Classification) String() string {
+	switch c {
+	case ProbablyPresent:
+		return "ProbablyPresent"
+	case ProbablyAbsent:
+		return "ProbablyAbsent"
+	}
+	return "Unknown"
+}
+
+// DualFilter pairs a bloom filter of values known to be present with one of
+// values confirmed absent, for caching lookups in both directions.
+type DualFilter struct {
+	Present *BloomFilter
+	Absent  *BloomFilter
+}
+
+// NewDual creates a dual filter whose two bloom filters have m bits and k hashing functions.
+func NewDual(m, k int) *DualFilter {
+	return &DualFilter{
+		Present: New(m, k),
+		Absent:  New(m, k),
+	}
+}
+
+// Classify tests v against both filters. A value in only one filter is
+// classified by that filter; a false positive in the other makes it Unknown.
+func (df *DualFilter) Classify(v []byte) Classification {
+	var present, absent = df.Present.Test(v), df.Absent.Test(v)
+	switch {
+	case present && !absent:
+		return ProbablyPresent
+	case absent && !present:
+		return ProbablyAbsent
+	}
+	return Unknown
+}
@@ -0,0 +1,24 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	df := NewDual(1000, 4)
+	df.Present.Add([]byte("here"))
+	df.Absent.Add([]byte("gone"))
+	df.Present.Add([]byte("both"))
+	df.Absent.Add([]byte("both"))
+	for v, want := range map[string]Classification{
+		"here":    ProbablyPresent,
+		"gone":    ProbablyAbsent,
+		"both":    Unknown,
+		"neither": Unknown,
+	} {
+		if c := df.Classify([]byte(v)); c != want {
+			t.Log(v, c)
+			t.Fail()
+		}
+	}
+}
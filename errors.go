@@ -0,0 +1,40 @@
+package bloomfilter
+
+import (
+	"errors"
+)
+
+var (
+	// ErrSizeMismatch is returned when filters of different sizes are combined.
+	ErrSizeMismatch error = mismatchError("bloomfilter: size mismatch")
+	// ErrKMismatch is returned when filters with different k are combined.
+	ErrKMismatch error = mismatchError("bloomfilter: k mismatch")
+	// ErrHashMismatch is returned when filters with different hashing schemes are combined.
+	ErrHashMismatch error = mismatchError("bloomfilter: hashing scheme mismatch")
+	// ErrIncompatible is returned when filters with different m, k or hashing scheme are compared or combined.
+	// ErrSizeMismatch, ErrKMismatch and ErrHashMismatch all match it with errors.Is.
+	ErrIncompatible = errors.New("bloomfilter: incompatible filters")
+	// ErrEmpty is returned when an operation is given no filters or readers to work on.
+	ErrEmpty = errors.New("bloomfilter: no filters")
+	// ErrInvalidFormat is returned when serialized data is not a valid bloom filter.
+	ErrInvalidFormat = errors.New("bloomfilter: invalid format")
+	// ErrNotEmpty is returned when an operation requires an empty filter.
+	ErrNotEmpty = errors.New("bloomfilter: filter is not empty")
+	// ErrHasherNotStored is returned when reading a filter that was written with a
+	// custom hasher, which serialized forms cannot store, without supplying the hasher.
+	ErrHasherNotStored = errors.New("bloomfilter: custom hasher not stored")
+	// ErrDegenerateHash is returned when a seed or hasher makes both base hashes equal.
+	ErrDegenerateHash = errors.New("bloomfilter: degenerate hash configuration")
+)
+
+// mismatchError is a specific reason two filters are incompatible
+type mismatchError string
+
+func (e mismatchError) Error() string {
+	return string(e)
+}
+
+// Is reports whether target is ErrIncompatible, so callers can match any mismatch
+func (e mismatchError) Is(target error) bool {
+	return target == ErrIncompatible
+}
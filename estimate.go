@@ -0,0 +1,349 @@
+package bloomfilter
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"runtime"
+)
+
+// PopCount returns the number of bits set in the bloom filter
+func (bf *BloomFilter) PopCount() int {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return popCount(bf.buckets)
+}
+
+// fill returns the number of bits set in the bloom filter together with its m, k
+// and number of bits set per key, all read under one read lock
+func (bf *BloomFilter) fill() (x int, m uint32, k int, size int) {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return popCount(bf.buckets), bf.m, bf.k, bf.hashing.size(bf.k)
+}
+
+func popCount(buckets []uint32) int {
+	var n = 0
+	for _, bucket := range buckets {
+		n += bits.OnesCount32(bucket)
+	}
+	return n
+}
+
+// ApproximateCount estimates the number of distinct items added to the bloom filter
+// from the fraction of bits set (Swamidass & Baldi).
+// A completely full filter returns math.MaxUint64.
+func (bf *BloomFilter) ApproximateCount() uint64 {
+	var x, m, _, size = bf.fill()
+	return approximateCount(x, m, size)
+}
+
+func approximateCount(x int, m uint32, k int) uint64 {
+	if x >= int(m) {
+		return math.MaxUint64
+	}
+	var n = -float64(m) / float64(k) * math.Log(1-float64(x)/float64(m))
+	return uint64(math.Round(n))
+}
+
+// ApproxAddedBetween estimates how many distinct items were added to a filter
+// between two snapshots of it, as the difference of their ApproximateCount.
+// Both snapshots must be Compatible, and every bit set in older must be set in newer.
+// The error of the estimate grows quickly as the filter approaches saturation.
+func ApproxAddedBetween(older, newer *BloomFilter) (uint64, error) {
+	if err := older.checkCompatible(newer); err != nil {
+		return 0, err
+	}
+	var a = older.snapshot()
+	var b = newer.snapshot()
+	for i := range a {
+		if a[i]&^b[i] != 0 {
+			return 0, errors.New("bloomfilter: older is not a snapshot of newer")
+		}
+	}
+	var na = approximateCount(popCount(a), older.m, older.hashing.size(older.k))
+	var nb = approximateCount(popCount(b), newer.m, newer.hashing.size(newer.k))
+	if nb == math.MaxUint64 {
+		return math.MaxUint64, nil
+	}
+	return nb - na, nil
+}
+
+// ExpectedSetBits returns the expected number of bits set in the bloom filter
+// after n distinct items are added, m*(1-(1-1/m)^(k*n)).
+func (bf *BloomFilter) ExpectedSetBits(n int) float64 {
+	var m, k, h = bf.params()
+	var kn = float64(h.size(k)) * float64(n)
+	return float64(m) * -math.Expm1(kn*math.Log1p(-1/float64(m)))
+}
+
+// EstimatedFPRate returns the false positive rate expected from the bloom filter's
+// current fill, the fraction of bits set raised to the power k, or k+1 with
+// WithSummaryBit since Test then checks the summary bit too.
+func (bf *BloomFilter) EstimatedFPRate() float64 {
+	var x, m, _, size = bf.fill()
+	return math.Pow(float64(x)/float64(m), float64(size))
+}
+
+// PositivePredictiveValue returns the probability that a positive Test result
+// is a true positive, by Bayes' rule, when a fraction priorPositiveRate of tested
+// values were added and false positives occur at EstimatedFPRate. Bloom filters
+// have no false negatives, so it is prior/(prior+(1-prior)*fp). It is 1 if the
+// estimated false positive rate is 0.
+func (bf *BloomFilter) PositivePredictiveValue(priorPositiveRate float64) float64 {
+	var fp = bf.EstimatedFPRate()
+	if fp == 0 {
+		return 1
+	}
+	return priorPositiveRate / (priorPositiveRate + (1-priorPositiveRate)*fp)
+}
+
+// AddsUntilFPRate estimates how many more distinct items can be added before
+// EstimatedFPRate exceeds p, from the bloom filter's current fill. It returns 0
+// if the filter is already past p, and math.MaxInt32 if p is 1 or more.
+func (bf *BloomFilter) AddsUntilFPRate(p float64) int {
+	if p >= 1 {
+		return math.MaxInt32
+	}
+	var x, m, _, size = bf.fill()
+	var fill = float64(x) / float64(m)
+	var target = math.Pow(p, 1/float64(size))
+	if fill >= target {
+		return 0
+	}
+	var n = float64(m) / float64(size) * (math.Log(1-fill) - math.Log(1-target))
+	if n > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(n)
+}
+
+// FPRateProjection returns the estimated false positive rate of the bloom
+// filter after each of steps evenly spaced numbers of further distinct adds,
+// the last being additional, starting from the filter's current fill. Rates
+// are computed like EstimatedFPRate, counting any summary bit both in the fill
+// and in the bits Test checks. It returns nil if steps is not positive.
+func (bf *BloomFilter) FPRateProjection(additional int, steps int) []float64 {
+	if steps < 1 {
+		return nil
+	}
+	var x, m, _, size = bf.fill()
+	var empty = 1 - float64(x)/float64(m)
+	var r = make([]float64, steps)
+	for i := range r {
+		var n = float64(additional) * float64(i+1) / float64(steps)
+		var fill = 1 - empty*math.Exp(-float64(size)*n/float64(m))
+		r[i] = math.Pow(fill, float64(size))
+	}
+	return r
+}
+
+// MeasureFPRate returns the fraction of negatives, items known to be absent,
+// that the bloom filter reports as present. It returns 0 if negatives is empty.
+func (bf *BloomFilter) MeasureFPRate(negatives [][]byte) float64 {
+	if len(negatives) == 0 {
+		return 0
+	}
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var n = 0
+	for _, v := range negatives {
+		if bf.test(v) {
+			n++
+		}
+	}
+	return float64(n) / float64(len(negatives))
+}
+
+// OptimalK returns the number of hashing functions that minimizes the false
+// positive rate of a bloom filter with m bits holding n items. It is at least 1.
+func OptimalK(m, n int) int {
+	if n < 1 {
+		n = 1
+	}
+	var k = int(math.Round(math.Log(2) * float64(m) / float64(n)))
+	if k < 1 {
+		k = 1
+	}
+	return k
+}
+
+// TheoreticalFPRate returns the expected false positive rate of a bloom filter
+// with m bits and k hashing functions after n distinct items have been added.
+func TheoreticalFPRate(m, k, n int) float64 {
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
+
+// InfoTheoreticBits returns n*log2(1/p), the fewest bits any structure can use
+// to answer membership for n items with false positive rate p.
+func InfoTheoreticBits(n int, p float64) float64 {
+	return float64(n) * math.Log2(1/p)
+}
+
+// SpaceOverhead returns the ratio of the bloom filter's m to InfoTheoreticBits(n, p).
+// An optimally sized bloom filter uses about 1/ln(2), or 44% more, than the bound.
+func (bf *BloomFilter) SpaceOverhead(n int, p float64) float64 {
+	return float64(bf.M()) / InfoTheoreticBits(n, p)
+}
+
+// BitsPerElement returns m divided by ApproximateCount, the number of bits the
+// bloom filter spends per item, for comparison with 1.44*log2(1/p) for an
+// optimally sized filter. An empty filter returns +Inf and a full one 0.
+func (bf *BloomFilter) BitsPerElement() float64 {
+	var n = bf.ApproximateCount()
+	if n == 0 {
+		return math.Inf(1)
+	}
+	if n == math.MaxUint64 {
+		return 0
+	}
+	return float64(bf.M()) / float64(n)
+}
+
+// NewBestEffort creates the bloom filter with the lowest false positive rate for
+// n items that fits in maxBytes, and returns it with that theoretical false positive rate.
+// The filter always has at least one 32-bit bucket, even if maxBytes is smaller.
+func NewBestEffort(n, maxBytes int) (*BloomFilter, float64) {
+	var m = maxBytes / 4 * 32
+	if m < 32 {
+		m = 32
+	}
+	var k = OptimalK(m, n)
+	return New(m, k), TheoreticalFPRate(m, k, n)
+}
+
+// SizeBytes returns the size of the bloom filter's buckets in bytes
+func (bf *BloomFilter) SizeBytes() int {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return len(bf.buckets) * 4
+}
+
+// RebuildFromIter creates a new bloom filter sized for estimatedN items at false
+// positive rate p and adds every key returned by next until it reports false.
+// This allows growing a saturated filter from an external key store without
+// buffering the keys.
+func RebuildFromIter(next func() ([]byte, bool), p float64, estimatedN int) (*BloomFilter, error) {
+	if estimatedN < 1 {
+		return nil, errors.New("bloomfilter: estimatedN must be positive")
+	}
+	if !(p > 0 && p < 1) {
+		return nil, errors.New("bloomfilter: p must be between 0 and 1")
+	}
+	var bf = New(EstimateParameters(estimatedN, p))
+	for v, ok := next(); ok; v, ok = next() {
+		bf.add(v)
+	}
+	return bf, nil
+}
+
+// parallelPopCountMin is the number of buckets below which ParallelPopCount
+// counts serially, since goroutine overhead outweighs the gain.
+const parallelPopCountMin = 1 << 16
+
+// ParallelPopCount returns the number of bits set in the bloom filter like PopCount,
+// splitting large filters into chunks counted by up to GOMAXPROCS goroutines.
+func (bf *BloomFilter) ParallelPopCount() int {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return parallelPopCount(bf.buckets, runtime.GOMAXPROCS(0))
+}
+
+func parallelPopCount(buckets []uint32, workers int) int {
+	if len(buckets) < parallelPopCountMin || workers < 2 {
+		return popCount(buckets)
+	}
+	var chunk = (len(buckets) + workers - 1) / workers
+	var counts = make(chan int, workers)
+	var started = 0
+	for i := 0; i < len(buckets); i += chunk {
+		var end = i + chunk
+		if end > len(buckets) {
+			end = len(buckets)
+		}
+		started++
+		go func(buckets []uint32) {
+			counts <- popCount(buckets)
+		}(buckets[i:end])
+	}
+	var n = 0
+	for i := 0; i < started; i++ {
+		n += <-counts
+	}
+	return n
+}
+
+// calibrateMaxK is the largest k tried by Calibrate
+const calibrateMaxK = 32
+
+// Calibrate builds filters of m bits holding samples with k from 1 to 32 and
+// returns the k with the lowest false positive rate measured against negatives,
+// items known to be absent from samples, together with that rate.
+// Ties go to the smaller k, which is cheaper to query, so Calibrate returns 1
+// if negatives is empty.
+func Calibrate(samples [][]byte, negatives [][]byte, m int) (bestK int, measuredFP float64) {
+	bestK, measuredFP = 1, math.Inf(1)
+	for k := 1; k <= calibrateMaxK; k++ {
+		var bf = New(m, k)
+		for _, v := range samples {
+			bf.add(v)
+		}
+		if p := bf.MeasureFPRate(negatives); p < measuredFP {
+			bestK, measuredFP = k, p
+		}
+	}
+	return bestK, measuredFP
+}
+
+// Count returns the number of distinct items added to the bloom filter. With
+// WithExactCount it is the number of adds that set a new bit since the filter
+// was created or reset; otherwise it is ApproximateCount.
+func (bf *BloomFilter) Count() uint64 {
+	if !bf.exactCount {
+		return bf.ApproximateCount()
+	}
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	return bf.count
+}
+
+// SuggestK returns the number of hashing functions that would minimize the false
+// positive rate of a filter with the bloom filter's m holding an estimated
+// ApproximateCount items. A value far from K suggests a poorly configured filter.
+// An empty filter suggests the largest useful k, and a full filter suggests 1.
+func (bf *BloomFilter) SuggestK() int {
+	var m = bf.M()
+	var n = bf.ApproximateCount()
+	if n > uint64(m) {
+		n = uint64(m)
+	}
+	return OptimalK(m, int(n))
+}
+
+// CollisionProbability returns the fraction of the k bit indexes of a that are
+// also bit indexes of b. It is 1 when every bit of a is set by b, which is how
+// adding b alone can make a test positive.
+func (bf *BloomFilter) CollisionProbability(a, b []byte) float64 {
+	var la = bf.Hashes(a)
+	var lb = map[uint32]bool{}
+	for _, l := range bf.Hashes(b) {
+		lb[l] = true
+	}
+	var n = 0
+	for _, l := range la {
+		if lb[l] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(la))
+}
+
+// FootprintSize returns the number of distinct bit indexes of v, which is less
+// than k when the double hashing stride cycles through a short orbit.
+func (bf *BloomFilter) FootprintSize(v []byte) int {
+	var seen = map[uint32]bool{}
+	for _, l := range bf.Hashes(v) {
+		seen[l] = true
+	}
+	return len(seen)
+}
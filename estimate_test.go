@@ -0,0 +1,416 @@
+package bloomfilter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestPopCount(t *testing.T) {
+	f := New(1000, 4)
+	if f.PopCount() != 0 {
+		t.Fail()
+	}
+	f.Add([]byte("abc"))
+	if n := f.PopCount(); n < 1 || n > 4 {
+		t.Log(n)
+		t.Fail()
+	}
+}
+
+func TestApproximateCount(t *testing.T) {
+	m, k := EstimateParameters(1000, 1e-3)
+	f := New(m, k)
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	if n := f.ApproximateCount(); n < 950 || n > 1050 {
+		t.Log(n)
+		t.Fail()
+	}
+}
+
+func TestApproxAddedBetween(t *testing.T) {
+	m, k := EstimateParameters(2000, 1e-3)
+	f := New(m, k)
+	for i := 0; i < 500; i++ {
+		f.AddInt(i)
+	}
+	older := NewFromBytes(f.ToBytes(), k)
+	for i := 500; i < 1500; i++ {
+		f.AddInt(i)
+	}
+	n, err := ApproxAddedBetween(older, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n < 950 || n > 1050 {
+		t.Log(n)
+		t.Fail()
+	}
+	if _, err := ApproxAddedBetween(f, older); err == nil {
+		t.Fail()
+	}
+	if _, err := ApproxAddedBetween(older, New(m+32, k)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+}
+
+func TestMeasureFPRate(t *testing.T) {
+	n := 1000
+	m, k := EstimateParameters(n, 1e-2)
+	f := New(m, k)
+	for i := 0; i < n; i++ {
+		f.AddInt(i)
+	}
+	negatives := make([][]byte, 100000)
+	for i := range negatives {
+		negatives[i] = []byte(fmt.Sprintf("negative-%d", i))
+	}
+	theoretical := TheoreticalFPRate(m, k, n)
+	actual := f.MeasureFPRate(negatives)
+	if actual < theoretical/2 || actual > theoretical*2 {
+		t.Log(theoretical, actual)
+		t.Fail()
+	}
+	if f.MeasureFPRate(nil) != 0 {
+		t.Fail()
+	}
+}
+
+func TestOptimalK(t *testing.T) {
+	m, k := EstimateParameters(10000, 1e-6)
+	if actual := OptimalK(m, 10000); actual != k && actual != k-1 {
+		t.Log(k, actual)
+		t.Fail()
+	}
+	if OptimalK(32, 1000) != 1 || OptimalK(32, 0) != 22 {
+		t.Fail()
+	}
+}
+
+func TestAddsUntilFPRate(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	f := New(m, k)
+	last := f.AddsUntilFPRate(0.01)
+	if last < 950 || last > 1050 {
+		t.Log(last)
+		t.Fail()
+	}
+	for i := 0; i < 2000; i += 100 {
+		for j := i; j < i+100; j++ {
+			f.AddInt(j)
+		}
+		n := f.AddsUntilFPRate(0.01)
+		if n > last || (n > 0 && f.EstimatedFPRate() > 0.01) {
+			t.Log(i, n, last)
+			t.Fail()
+		}
+		last = n
+	}
+	if last != 0 {
+		t.Log(last)
+		t.Fail()
+	}
+	if f.AddsUntilFPRate(0) != 0 || f.AddsUntilFPRate(1) <= 0 {
+		t.Fail()
+	}
+}
+
+func TestExpectedSetBits(t *testing.T) {
+	const trials, n = 20, 500
+	var sum float64
+	for seed := uint64(0); seed < trials; seed++ {
+		f := NewWithOptions(4096, 4, WithSeed(seed))
+		for i := 0; i < n; i++ {
+			f.AddInt(i)
+		}
+		sum += float64(f.PopCount())
+	}
+	mean := sum / trials
+	expected := New(4096, 4).ExpectedSetBits(n)
+	t.Log(mean, expected)
+	if mean < expected*0.98 || mean > expected*1.02 {
+		t.Fail()
+	}
+	if New(4096, 4).ExpectedSetBits(0) != 0 {
+		t.Fail()
+	}
+}
+
+func TestFPRateProjection(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	f := New(m, k)
+	for i := 0; i < 500; i++ {
+		f.AddInt(i)
+	}
+	r := f.FPRateProjection(500, 10)
+	if len(r) != 10 {
+		t.Fatal(len(r))
+	}
+	last := f.EstimatedFPRate()
+	for i, p := range r {
+		if p <= last {
+			t.Log(i, p, last)
+			t.Fail()
+		}
+		last = p
+	}
+	if math.Abs(r[9]-0.01) > 0.003 {
+		t.Log(r[9])
+		t.Fail()
+	}
+	if f.FPRateProjection(500, 0) != nil || f.FPRateProjection(500, -1) != nil {
+		t.Fail()
+	}
+	// the summary bit fills the filter faster, which outweighs checking one more bit
+	s := NewWithOptions(m, k, WithSummaryBit())
+	if s.FPRateProjection(0, 1)[0] != 0 || s.FPRateProjection(1000, 1)[0] <= New(m, k).FPRateProjection(1000, 1)[0] {
+		t.Log(s.FPRateProjection(1000, 1), New(m, k).FPRateProjection(1000, 1))
+		t.Fail()
+	}
+}
+
+func TestPositivePredictiveValue(t *testing.T) {
+	f := New(64, 1)
+	f.buckets[0] = 0xffffffff
+	// fp = 0.5: 0.1/(0.1+0.9*0.5)
+	for prior, want := range map[float64]float64{0.1: 0.1 / 0.55, 0.5: 2.0 / 3, 0: 0, 1: 1} {
+		if ppv := f.PositivePredictiveValue(prior); math.Abs(ppv-want) > 1e-12 {
+			t.Log(prior, ppv, want)
+			t.Fail()
+		}
+	}
+	f = New(64, 2)
+	f.buckets[0] = 0xffffffff
+	// fp = 0.25: 0.2/(0.2+0.8*0.25)
+	if ppv := f.PositivePredictiveValue(0.2); math.Abs(ppv-0.5) > 1e-12 {
+		t.Log(ppv)
+		t.Fail()
+	}
+	if New(64, 2).PositivePredictiveValue(0.2) != 1 {
+		t.Fail()
+	}
+}
+
+func TestSuggestK(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	f := New(m, k)
+	poor := New(m, 1)
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+		poor.AddInt(i)
+	}
+	if f.SuggestK() != k {
+		t.Log(k, f.SuggestK())
+		t.Fail()
+	}
+	if poor.SuggestK() != k {
+		t.Log(k, poor.SuggestK())
+		t.Fail()
+	}
+	full := New(32, 1)
+	full.buckets[0] = 0xffffffff
+	if full.SuggestK() != 1 {
+		t.Fail()
+	}
+}
+
+func TestInfoTheoreticBits(t *testing.T) {
+	if InfoTheoreticBits(1000, 1.0/1024) != 10000 || InfoTheoreticBits(1, 0.5) != 1 {
+		t.Fail()
+	}
+	f := New(EstimateParameters(1000000, 0.01))
+	if o := f.SpaceOverhead(1000000, 0.01); o < 1.44 || o > 1.45 {
+		t.Log(o)
+		t.Fail()
+	}
+}
+
+func TestBitsPerElement(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	f := New(m, k)
+	if !math.IsInf(f.BitsPerElement(), 1) {
+		t.Fail()
+	}
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	// an optimally sized filter spends 1.44*log2(100), about 9.6, bits per item
+	if b := f.BitsPerElement(); b < 9.3 || b > 9.9 {
+		t.Log(b)
+		t.Fail()
+	}
+	full := New(32, 1)
+	full.buckets[0] = 0xffffffff
+	if full.BitsPerElement() != 0 {
+		t.Fail()
+	}
+}
+
+func TestNewBestEffort(t *testing.T) {
+	for _, maxBytes := range []int{1, 100, 1001, 1 << 20} {
+		f, p := NewBestEffort(10000, maxBytes)
+		if maxBytes >= 4 && f.SizeBytes() > maxBytes {
+			t.Log(maxBytes, f.SizeBytes())
+			t.Fail()
+		}
+		if p != TheoreticalFPRate(int(f.m), f.k, 10000) {
+			t.Log(maxBytes, p)
+			t.Fail()
+		}
+	}
+	_, small := NewBestEffort(10000, 1000)
+	_, large := NewBestEffort(10000, 100000)
+	if large >= small {
+		t.Fail()
+	}
+}
+
+func TestRebuildFromIter(t *testing.T) {
+	keys := [][]byte{[]byte("abc"), []byte("def"), []byte("ghi")}
+	i := 0
+	next := func() ([]byte, bool) {
+		if i == len(keys) {
+			return nil, false
+		}
+		i++
+		return keys[i-1], true
+	}
+	f, err := RebuildFromIter(next, 1e-3, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, k := EstimateParameters(100, 1e-3)
+	if int(f.m) != m || f.k != k {
+		t.Log(f.m, f.k)
+		t.Fail()
+	}
+	for _, v := range keys {
+		if !f.Test(v) {
+			t.Log(string(v))
+			t.Fail()
+		}
+	}
+	if f.Test([]byte("jkl")) {
+		t.Fail()
+	}
+	if _, err := RebuildFromIter(next, 0, 100); err == nil {
+		t.Fail()
+	}
+	if _, err := RebuildFromIter(next, 1e-3, 0); err == nil {
+		t.Fail()
+	}
+}
+
+func TestParallelPopCount(t *testing.T) {
+	for _, m := range []int{1000, parallelPopCountMin * 32 * 3} {
+		f := New(m, 4)
+		for i := 0; i < 10000; i++ {
+			f.AddInt(i)
+		}
+		if f.ParallelPopCount() != f.PopCount() {
+			t.Log(m, f.ParallelPopCount(), f.PopCount())
+			t.Fail()
+		}
+		for _, workers := range []int{2, 3, 7} {
+			if n := parallelPopCount(f.buckets, workers); n != f.PopCount() {
+				t.Log(m, workers, n)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func BenchmarkPopCount(b *testing.B) {
+	f := New(1<<28, 4)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.PopCount()
+	}
+}
+
+func BenchmarkParallelPopCount(b *testing.B) {
+	f := New(1<<28, 4)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.ParallelPopCount()
+	}
+}
+
+func TestCalibrate(t *testing.T) {
+	samples := make([][]byte, 1000)
+	for i := range samples {
+		samples[i] = []byte(fmt.Sprintf("sample-%d", i))
+	}
+	negatives := make([][]byte, 20000)
+	for i := range negatives {
+		negatives[i] = []byte(fmt.Sprintf("negative-%d", i))
+	}
+	m := 10000
+	k, p := Calibrate(samples, negatives, m)
+	optimal := OptimalK(m, len(samples))
+	if k < optimal-3 || k > optimal+3 {
+		t.Log(optimal, k)
+		t.Fail()
+	}
+	if p > 2*TheoreticalFPRate(m, optimal, len(samples)) {
+		t.Log(p)
+		t.Fail()
+	}
+}
+
+// tableHasher hashes each key to a fixed 64-bit value
+type tableHasher struct {
+	table *map[string]uint64
+}
+
+func (h tableHasher) Hash(v []byte) uint64 {
+	return (*h.table)[string(v)]
+}
+
+func TestCollisionProbability(t *testing.T) {
+	table := map[string]uint64{
+		"a": 0<<32 | 1,  // 0 1 2 3
+		"b": 2<<32 | 1,  // 2 3 4 5
+		"c": 10<<32 | 1, // 10 11 12 13
+		"d": 1<<32 | 2,  // 1 3 5 7
+	}
+	f := NewWithOptions(64, 4, WithHasher(tableHasher{&table}))
+	for _, c := range []struct {
+		a, b     string
+		expected float64
+	}{
+		{"a", "a", 1},
+		{"a", "b", 0.5},
+		{"a", "c", 0},
+		{"a", "d", 0.5},
+		{"b", "d", 0.5},
+	} {
+		if p := f.CollisionProbability([]byte(c.a), []byte(c.b)); p != c.expected {
+			t.Log(c.a, c.b, p)
+			t.Fail()
+		}
+	}
+}
+
+func TestFootprintSize(t *testing.T) {
+	table := map[string]uint64{
+		"distinct": 0<<32 | 1,  // 0 1 2 3 4 5
+		"zero":     5<<32 | 0,  // 5 5 5 5 5 5
+		"half":     3<<32 | 32, // 3 35 3 35 3 35
+		"quarter":  1<<32 | 80, // 1 17 33 49 1 17
+	}
+	f := NewWithOptions(64, 6, WithHasher(tableHasher{&table}))
+	for key, expected := range map[string]int{"distinct": 6, "zero": 1, "half": 2, "quarter": 4} {
+		if n := f.FootprintSize([]byte(key)); n != expected {
+			t.Log(key, n)
+			t.Fail()
+		}
+	}
+	if n := New(1000, 4).FootprintSize([]byte("abc")); n != 4 {
+		t.Log(n)
+		t.Fail()
+	}
+}
@@ -0,0 +1,19 @@
+package bloomfilter_test
+
+import (
+	"fmt"
+
+	"github.com/jda/bloomfilter"
+)
+
+func ExampleNegativeCache() {
+	nc := bloomfilter.NewNegativeCache(1000, 4)
+	nc.MarkSeen([]byte("foo"))
+	// A marked key is never reported as new.
+	fmt.Println(nc.MightBeNew([]byte("foo")))
+	// An unmarked key is usually reported as new. When it is, that is certain.
+	fmt.Println(nc.MightBeNew([]byte("bar")))
+	// Output:
+	// false
+	// true
+}
@@ -0,0 +1,47 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+)
+
+// AddFields adds a composite key made of fields to the bloom filter.
+// Each field is length-prefixed before hashing, so ("ab", "c") and ("a", "bc")
+// are different keys.
+func (bf *BloomFilter) AddFields(fields ...[]byte) {
+	bf.Add(encodeFields(fields))
+}
+
+// TestFields evaluates a composite key added with AddFields to determine whether
+// it is (probably) in the bloom filter
+func (bf *BloomFilter) TestFields(fields ...[]byte) bool {
+	return bf.Test(encodeFields(fields))
+}
+
+// encodeFields concatenates fields, each prefixed with its big-endian 32-bit length
+func encodeFields(fields [][]byte) []byte {
+	var n = 0
+	for _, f := range fields {
+		n += 4 + len(f)
+	}
+	var bb = make([]byte, 0, n)
+	var a = make([]byte, 4)
+	for _, f := range fields {
+		binary.BigEndian.PutUint32(a, uint32(len(f)))
+		bb = append(bb, a...)
+		bb = append(bb, f...)
+	}
+	return bb
+}
+
+// AddInDomain adds v to the bloom filter under the domain-separation tag, so the
+// same v under different tags maps to different bits. Use it to share one filter
+// between logical filters whose keys overlap. It is equivalent to AddFields(tag, v).
+func (bf *BloomFilter) AddInDomain(tag, v []byte) {
+	bf.AddFields(tag, v)
+}
+
+// TestInDomain evaluates v under the domain-separation tag to determine whether
+// it was (probably) added with AddInDomain
+func (bf *BloomFilter) TestInDomain(tag, v []byte) bool {
+	return bf.TestFields(tag, v)
+}
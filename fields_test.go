@@ -0,0 +1,43 @@
+package bloomfilter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	f := New(1000, 4)
+	f.AddFields([]byte("ab"), []byte("c"))
+	if !f.TestFields([]byte("ab"), []byte("c")) {
+		t.Fail()
+	}
+	if f.TestFields([]byte("a"), []byte("bc")) {
+		t.Fail()
+	}
+	if f.Test([]byte("abc")) {
+		t.Fail()
+	}
+}
+
+func TestFieldsBoundaries(t *testing.T) {
+	f := New(1000, 4)
+	l1 := f.locations(encodeFields([][]byte{[]byte("ab"), []byte("c")}))
+	l2 := f.locations(encodeFields([][]byte{[]byte("a"), []byte("bc")}))
+	if reflect.DeepEqual(l1, l2) {
+		t.Log(l1, l2)
+		t.Fail()
+	}
+}
+
+func TestAddInDomain(t *testing.T) {
+	f := New(1000, 4)
+	v := []byte("key")
+	a, b := []byte("users"), []byte("orders")
+	if reflect.DeepEqual(f.Hashes(encodeFields([][]byte{a, v})), f.Hashes(encodeFields([][]byte{b, v}))) {
+		t.Fail()
+	}
+	f.AddInDomain(a, v)
+	if !f.TestInDomain(a, v) || f.TestInDomain(b, v) || f.Test(v) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,148 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"sync"
+)
+
+var errMmapUnsupported = errors.New("bloomfilter: file-backed filters are not supported on this platform")
+
+// FileBackedFilter is a bloom filter whose bits live in a memory-mapped file in
+// the aligned format, so the operating system's page cache manages filters
+// larger than is comfortable to hold in memory.
+//
+// A FileBackedFilter is safe for concurrent use within one process, but there
+// is no locking between processes mapping the same file. Writes reach the file
+// at the operating system's discretion until Sync returns; after a crash the
+// file may hold any mix of bits set before and after the last Sync, though
+// bits set before it are never lost.
+type FileBackedFilter struct {
+	m       uint32
+	k       int
+	file    *os.File
+	mapped  []byte
+	buckets []byte
+	lock    sync.RWMutex
+}
+
+// NewFileBacked opens the file-backed bloom filter at path, creating it with
+// m bits and k hashing functions if it does not exist or is empty.
+// m is rounded up to the nearest multiple of 32. An existing file must have
+// been created with the same m and k.
+func NewFileBacked(path string, m, k int) (*FileBackedFilter, error) {
+	var n = uint32(math.Ceil(float64(m) / 32))
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	var headerSize = alignedHeaderSize
+	if fi.Size() == 0 {
+		err = initFileBacked(f, n*32, uint32(k))
+	} else {
+		headerSize, err = checkFileBacked(f, n*32, uint32(k))
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	mapped, err := mmapFile(f, headerSize+alignedSize(int(n))-alignedHeaderSize)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileBackedFilter{
+		m:       n * 32,
+		k:       k,
+		file:    f,
+		mapped:  mapped,
+		buckets: mapped[headerSize : uint32(headerSize)+n*4],
+	}, nil
+}
+
+// initFileBacked writes the header and extends f to its full size without writing the zero buckets
+func initFileBacked(f *os.File, m, k uint32) error {
+	var header = make([]byte, alignedHeaderSize)
+	putAlignedHeader(header, m, k, 0, 0)
+	if _, err := f.WriteAt(header, 0); err != nil {
+		return err
+	}
+	return f.Truncate(int64(alignedSize(int(m / 32))))
+}
+
+// checkFileBacked checks that the header of f matches m and k and returns its size
+func checkFileBacked(f *os.File, m, k uint32) (int, error) {
+	_, h, err := readAlignedHeader(io.NewSectionReader(f, 0, alignedHeaderSize))
+	if err == io.ErrUnexpectedEOF {
+		return 0, ErrInvalidFormat
+	} else if err != nil {
+		return 0, err
+	}
+	switch {
+	case h.m != m:
+		return 0, ErrSizeMismatch
+	case h.k != k:
+		return 0, ErrKMismatch
+	case h.flags != 0 || h.seed != 0:
+		return 0, ErrHashMismatch
+	}
+	if fi, err := f.Stat(); err != nil {
+		return 0, err
+	} else if fi.Size() < int64(h.size+alignedSize(int(m/32))-alignedHeaderSize) {
+		return 0, ErrInvalidFormat
+	}
+	return h.size, nil
+}
+
+// Add adds a byte array to the file-backed bloom filter
+func (fb *FileBackedFilter) Add(v []byte) {
+	fb.lock.Lock()
+	defer fb.lock.Unlock()
+	for _, l := range locations(v, fb.m, fb.k) {
+		var w = fb.buckets[l/32*4:]
+		binary.BigEndian.PutUint32(w, binary.BigEndian.Uint32(w)|1<<(l%32))
+	}
+}
+
+// Test evaluates a byte array to determine whether it is (probably) in the file-backed bloom filter
+func (fb *FileBackedFilter) Test(v []byte) bool {
+	fb.lock.RLock()
+	defer fb.lock.RUnlock()
+	for _, l := range locations(v, fb.m, fb.k) {
+		if (binary.BigEndian.Uint32(fb.buckets[l/32*4:]) & (1 << (l % 32))) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Sync flushes changes to the file and waits for the write to complete
+func (fb *FileBackedFilter) Sync() error {
+	fb.lock.RLock()
+	defer fb.lock.RUnlock()
+	return msync(fb.mapped)
+}
+
+// Close syncs and unmaps the file-backed bloom filter and closes its file.
+// The filter must not be used after Close.
+func (fb *FileBackedFilter) Close() error {
+	fb.lock.Lock()
+	defer fb.lock.Unlock()
+	var err = msync(fb.mapped)
+	if uerr := munmap(fb.mapped); err == nil {
+		err = uerr
+	}
+	if cerr := fb.file.Close(); err == nil {
+		err = cerr
+	}
+	fb.mapped, fb.buckets = nil, nil
+	return err
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package bloomfilter
+
+import (
+	"os"
+)
+
+func mmapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+func msync(b []byte) error {
+	return errMmapUnsupported
+}
+
+func munmap(b []byte) error {
+	return errMmapUnsupported
+}
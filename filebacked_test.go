@@ -0,0 +1,49 @@
+package bloomfilter
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileBacked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter.bin")
+	fb, err := NewFileBacked(path, 100000, 4)
+	if err == errMmapUnsupported {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	fb.Add([]byte("abc"))
+	if !fb.Test([]byte("abc")) || fb.Test([]byte("def")) {
+		t.Fail()
+	}
+	if err := fb.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fb, err = NewFileBacked(path, 100000, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fb.Close()
+	if !fb.Test([]byte("abc")) || fb.Test([]byte("def")) {
+		t.Fail()
+	}
+	bb, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mf, err := NewFromMmap(bb)
+	if err != nil || !mf.Test([]byte("abc")) {
+		t.Fail()
+	}
+	if _, err := NewFileBacked(path, 200000, 4); !errors.Is(err, ErrSizeMismatch) {
+		t.Log(err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,51 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+)
+
+// FrozenFilter is an immutable, lock-free bloom filter.
+// It is safe for concurrent use because it is never written after creation.
+type FrozenFilter struct {
+	m       uint32
+	k       int
+	hashing hashing
+	buckets []uint32
+	// overrides is a copy of the bloom filter's MarkExact keys, if any
+	overrides map[string]bool
+}
+
+// Freeze returns a point-in-time copy of the bloom filter as a FrozenFilter.
+// Later changes to the bloom filter are not reflected in the copy. Keys marked
+// with MarkExact keep their exact answers in the copy.
+func (bf *BloomFilter) Freeze() *FrozenFilter {
+	var buckets = bf.lockedSnapshot()
+	defer bf.lock.RUnlock()
+	return &FrozenFilter{
+		m:         bf.m,
+		k:         bf.k,
+		hashing:   bf.hashing,
+		buckets:   buckets,
+		overrides: copyOverrides(bf.overrides),
+	}
+}
+
+// Test evaluates a byte array to determine whether it is (probably) in the frozen filter
+func (ff *FrozenFilter) Test(v []byte) bool {
+	if present, ok := ff.overrides[string(v)]; ok {
+		return present
+	}
+	for _, l := range ff.hashing.locations(v, ff.m, ff.k) {
+		if (ff.buckets[l/32] & (1 << (l % 32))) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestInt evaluates an int to determine whether it is (probably) in the frozen filter
+func (ff *FrozenFilter) TestInt(v int) bool {
+	var a = make([]byte, 4)
+	binary.BigEndian.PutUint32(a, uint32(v))
+	return ff.Test(a)
+}
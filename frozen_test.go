@@ -0,0 +1,81 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	f.AddInt(1)
+	ff := f.Freeze()
+	f.Add([]byte("def"))
+	if !ff.Test([]byte("abc")) || !ff.TestInt(1) {
+		t.Fail()
+	}
+	if ff.Test([]byte("def")) {
+		t.Fail()
+	}
+}
+
+func BenchmarkParallelTest(b *testing.B) {
+	m, k := EstimateParameters(10000, 1e-4)
+	f := New(m, k)
+	for i := 0; i < 10000; i++ {
+		f.AddInt(i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		key := make([]byte, 4)
+		i := 0
+		for pb.Next() {
+			binary.BigEndian.PutUint32(key, uint32(i))
+			f.Test(key)
+			i++
+		}
+	})
+}
+
+func BenchmarkParallelFrozenTest(b *testing.B) {
+	m, k := EstimateParameters(10000, 1e-4)
+	f := New(m, k)
+	for i := 0; i < 10000; i++ {
+		f.AddInt(i)
+	}
+	ff := f.Freeze()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		key := make([]byte, 4)
+		i := 0
+		for pb.Next() {
+			binary.BigEndian.PutUint32(key, uint32(i))
+			ff.Test(key)
+			i++
+		}
+	})
+}
+
+func TestFreezeConcurrentReset(t *testing.T) {
+	f := New(1000, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for seed := uint64(1); seed <= 1000; seed++ {
+			f.ResetWithSeed(seed)
+			f.Add([]byte("abc"))
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		// the buckets and seed are copied together, so any bits set belong to the frozen seed
+		ff := f.Freeze()
+		if popCount(ff.buckets) != 0 && !ff.Test([]byte("abc")) {
+			t.Fatal(ff.hashing.seed)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.18
+// +build go1.18
+
+package bloomfilter
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// FuzzNoFalseNegatives splits the input on zero bytes, adds every piece to a
+// filter sized for them and checks that each one tests positive and that the
+// false positive rate on items not in the set stays plausible.
+func FuzzNoFalseNegatives(f *testing.F) {
+	f.Add([]byte("abc"))
+	f.Add([]byte("abc\x00def\x00ghi"))
+	f.Add([]byte(jabberwocky))
+	f.Add([]byte("Ā\x00ā\x00ă"))
+	f.Add([]byte{0, 0, 0, 0xff, 0xfe})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		items := bytes.Split(data, []byte{0})
+		present := map[string]bool{}
+		for _, v := range items {
+			present[string(v)] = true
+		}
+		// Size for at least 100 items: filters of only a few buckets have too
+		// few distinct strides for the false positive check to be meaningful.
+		n := len(present)
+		if n < 100 {
+			n = 100
+		}
+		m, k := EstimateParameters(n, 1e-2)
+		bf := New(m, k)
+		for _, v := range items {
+			bf.Add(v)
+		}
+		for _, v := range items {
+			if !bf.Test(v) {
+				t.Fatalf("false negative for %q", v)
+			}
+		}
+		probes, positives := 0, 0
+		for i := 0; probes < 1000; i++ {
+			v := fmt.Sprintf("absent-%d", i)
+			if present[v] {
+				continue
+			}
+			probes++
+			if bf.Test([]byte(v)) {
+				positives++
+			}
+		}
+		if positives > 100 {
+			t.Fatalf("false positive rate %d/%d", positives, probes)
+		}
+	})
+}
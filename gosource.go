@@ -0,0 +1,40 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GoSource returns a Go variable declaration named varName that recreates the
+// bloom filter with NewFromBuckets, for embedding a precomputed filter in a
+// binary from a go:generate step. The enclosing file must import this package.
+// Enhanced hashing, summary bits and the seed are kept; a custom Hasher is not.
+func (bf *BloomFilter) GoSource(varName string) string {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "var %s = bloomfilter.NewFromBuckets([]uint32{", varName)
+	for i, bucket := range bf.buckets {
+		if i%6 == 0 {
+			sb.WriteString("\n\t")
+		} else {
+			sb.WriteString(" ")
+		}
+		fmt.Fprintf(&sb, "0x%08x,", bucket)
+	}
+	if len(bf.buckets) > 0 {
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "}, %d", bf.k)
+	if bf.hashing.enhanced {
+		sb.WriteString(", bloomfilter.WithEnhancedHashing()")
+	}
+	if bf.hashing.summary {
+		sb.WriteString(", bloomfilter.WithSummaryBit()")
+	}
+	if bf.hashing.seed != 0 {
+		fmt.Fprintf(&sb, ", bloomfilter.WithSeed(%d)", bf.hashing.seed)
+	}
+	sb.WriteString(")\n")
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package bloomfilter
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseGoSource parses the output of GoSource and returns the buckets literal
+// and the remaining arguments of the NewFromBuckets call
+func parseGoSource(t *testing.T, src string) ([]uint32, []string) {
+	file := "package p\n\nimport \"github.com/jda/bloomfilter\"\n\n" + src
+	formatted, err := format.Source([]byte(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != file {
+		t.Log("GoSource output is not gofmt formatted")
+		t.Fail()
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", file, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := f.Decls[1].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.CallExpr)
+	if sel := call.Fun.(*ast.SelectorExpr); sel.Sel.Name != "NewFromBuckets" {
+		t.Fatal(sel.Sel.Name)
+	}
+	var buckets = []uint32{}
+	for _, e := range call.Args[0].(*ast.CompositeLit).Elts {
+		v, err := strconv.ParseUint(e.(*ast.BasicLit).Value, 0, 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buckets = append(buckets, uint32(v))
+	}
+	var args []string
+	for _, a := range call.Args[1:] {
+		args = append(args, file[a.Pos()-1:a.End()-1])
+	}
+	return buckets, args
+}
+
+func TestGoSource(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	src := f.GoSource("users")
+	if !strings.HasPrefix(src, "var users = ") {
+		t.Fail()
+	}
+	buckets, args := parseGoSource(t, src)
+	if !reflect.DeepEqual(buckets, f.buckets) || !reflect.DeepEqual(args, []string{"4"}) {
+		t.Log(args)
+		t.Fail()
+	}
+	g := NewFromBuckets(buckets, 4)
+	if !g.Compatible(f) || !g.Test([]byte("abc")) {
+		t.Fail()
+	}
+
+	e := NewWithOptions(64, 3, WithEnhancedHashing(), WithSeed(7))
+	buckets, args = parseGoSource(t, e.GoSource("e"))
+	if len(buckets) != 2 || !reflect.DeepEqual(args, []string{"3", "bloomfilter.WithEnhancedHashing()", "bloomfilter.WithSeed(7)"}) {
+		t.Log(args)
+		t.Fail()
+	}
+	if !NewFromBuckets(buckets, 3, WithEnhancedHashing(), WithSeed(7)).Compatible(e) {
+		t.Fail()
+	}
+	buckets, _ = parseGoSource(t, New(0, 1).GoSource("empty"))
+	if len(buckets) != 0 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,64 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"io"
+	"sync/atomic"
+)
+
+// hashedChunk is the number of hashes AddHashedStream reads before taking the lock
+const hashedChunk = 512
+
+// AddHashedStream reads big-endian 64-bit hashes from r until EOF and adds each
+// to the bloom filter, using the high and low 32 bits as the two base hashes.
+// It returns the number of hashes added. A trailing partial hash is reported
+// as io.ErrUnexpectedEOF. The lock is not held while reading from r.
+func (bf *BloomFilter) AddHashedStream(r io.Reader) (int, error) {
+	var buf = make([]byte, hashedChunk*8)
+	var count = 0
+	for {
+		n, err := io.ReadFull(r, buf)
+		bf.addHashes(buf[:n-n%8])
+		count += n / 8
+		switch {
+		case err == io.EOF:
+			return count, nil
+		case err == io.ErrUnexpectedEOF:
+			if n%8 != 0 {
+				return count, err
+			}
+			return count, nil
+		case err != nil:
+			return count, err
+		}
+	}
+}
+
+// TestHashed evaluates a 64-bit hash added with AddHashedStream to determine
+// whether it is (probably) in the bloom filter
+func (bf *BloomFilter) TestHashed(h uint64) bool {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	for _, l := range bf.hashLocations(uint32(h>>32), uint32(h)) {
+		if (bf.buckets[l/32] & (1 << (l % 32))) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (bf *BloomFilter) addHashes(bb []byte) {
+	if len(bb) == 0 {
+		return
+	}
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if bf.stats {
+		atomic.AddUint64(&bf.adds, uint64(len(bb)/8))
+	}
+	for i := 0; i+8 <= len(bb); i += 8 {
+		var a = binary.BigEndian.Uint32(bb[i:])
+		var b = binary.BigEndian.Uint32(bb[i+4:])
+		bf.set(bf.hashLocations(a, b))
+	}
+}
@@ -0,0 +1,45 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestAddHashedStream(t *testing.T) {
+	f := New(100000, 4)
+	var buf bytes.Buffer
+	hashes := make([]uint64, 1000)
+	for i := range hashes {
+		hashes[i] = uint64(i)*0x9e3779b97f4a7c15 + 1
+		binary.Write(&buf, binary.BigEndian, hashes[i])
+	}
+	n, err := f.AddHashedStream(&buf)
+	if err != nil || n != len(hashes) {
+		t.Log(n, err)
+		t.Fail()
+	}
+	for _, h := range hashes {
+		if !f.TestHashed(h) {
+			t.Fatal(h)
+		}
+	}
+	if f.TestHashed(0x1234567890abcdef) {
+		t.Fail()
+	}
+	for _, l := range hashLocations(uint32(hashes[0]>>32), uint32(hashes[0]), f.m, f.k) {
+		if f.buckets[l/32]&(1<<(l%32)) == 0 {
+			t.Fail()
+		}
+	}
+}
+
+func TestAddHashedStreamPartial(t *testing.T) {
+	f := New(1000, 4)
+	n, err := f.AddHashedStream(bytes.NewReader(make([]byte, 20)))
+	if n != 2 || err != io.ErrUnexpectedEOF {
+		t.Log(n, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,102 @@
+package bloomfilter
+
+import (
+	"math"
+)
+
+// HealthReport describes the saturation of a bloom filter.
+type HealthReport struct {
+	FillRatio    float64 // fraction of bits set
+	FPRate       float64 // estimated false positive rate at the current fill
+	Overloaded   bool    // FPRate is more than twice the target rate
+	RecommendedM int     // m needed to hold the estimated item count at the target rate
+	RecommendedK int     // k needed to hold the estimated item count at the target rate
+}
+
+// Health reports how saturated the bloom filter is and what parameters it would
+// need to serve its estimated item count at p, the false positive rate the
+// filter was designed for. If p is not between 0 and 1, the target is taken to
+// be 0.5^k, the rate at which k is optimal for m.
+func (bf *BloomFilter) Health(p float64) HealthReport {
+	var x, m, k, size = bf.fill()
+	if p <= 0 || p >= 1 {
+		p = math.Pow(0.5, float64(k))
+	}
+	var fill = float64(x) / float64(m)
+	var r = HealthReport{
+		FillRatio:    fill,
+		FPRate:       math.Pow(fill, float64(size)),
+		RecommendedM: int(m),
+		RecommendedK: k,
+	}
+	r.Overloaded = r.FPRate > 2*p
+	if r.Overloaded {
+		_, r.RecommendedM, r.RecommendedK = sizeFor(x, m, size, p)
+	}
+	return r
+}
+
+// sizeFor estimates the item count of a bloom filter of m bits with x bits set
+// and size bits set per key, and the m and k that would hold that count at
+// false positive rate p.
+func sizeFor(x int, m uint32, size int, p float64) (n uint64, optimalM, optimalK int) {
+	// A full filter gives no upper bound on n; size for the lower bound instead.
+	if x >= int(m) {
+		x = int(m) - 1
+	}
+	n = approximateCount(x, m, size)
+	// EstimateParameters divides by the item count, so size for at least one
+	var sized = n
+	if sized < 1 {
+		sized = 1
+	}
+	optimalM, optimalK = EstimateParameters(int(sized), p)
+	return n, optimalM, optimalK
+}
+
+// IsSaturated reports whether the fraction of bits set in the bloom filter exceeds
+// threshold, or 0.5 if threshold is not positive. An optimally filled filter has
+// half its bits set; well beyond that, Test returns true for nearly every value.
+func (bf *BloomFilter) IsSaturated(threshold float64) bool {
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	var x, m, _, _ = bf.fill()
+	return float64(x)/float64(m) > threshold
+}
+
+// autoTuneTolerance is how far m or k may be from optimal before AutoTuneReport recommends a rebuild
+const autoTuneTolerance = 0.25
+
+// TuneReport compares a bloom filter's parameters to those its workload needs.
+type TuneReport struct {
+	FPRate     float64 // estimated false positive rate at the current fill
+	EstimatedN uint64  // ApproximateCount
+	OptimalM   int     // m needed to hold EstimatedN items at the target rate
+	OptimalK   int     // k needed to hold EstimatedN items at the target rate
+	Rebuild    bool    // m or k is more than 25% away from optimal
+}
+
+// AutoTuneReport estimates the bloom filter's item count and the m and k that
+// would hold it at false positive rate p, the rate the filter was designed for,
+// and recommends a rebuild when the actual m or k is more than 25% away from
+// them. An empty filter reports its own m and k and no rebuild. Like Health,
+// it takes the target to be 0.5^k if p is not between 0 and 1.
+func (bf *BloomFilter) AutoTuneReport(p float64) TuneReport {
+	var x, m, k, size = bf.fill()
+	if p <= 0 || p >= 1 {
+		p = math.Pow(0.5, float64(k))
+	}
+	var r = TuneReport{
+		FPRate:   math.Pow(float64(x)/float64(m), float64(size)),
+		OptimalM: int(m),
+		OptimalK: k,
+	}
+	if x == 0 {
+		return r
+	}
+	r.EstimatedN, r.OptimalM, r.OptimalK = sizeFor(x, m, size, p)
+	r.Rebuild = math.Abs(float64(int(m)-r.OptimalM)) > autoTuneTolerance*float64(r.OptimalM) ||
+		math.Abs(float64(k-r.OptimalK)) > autoTuneTolerance*float64(r.OptimalK)
+	return r
+}
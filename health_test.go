@@ -0,0 +1,135 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestHealth(t *testing.T) {
+	m, k := EstimateParameters(1000, 1e-2)
+	f := New(m, k)
+	for i := 0; i < 500; i++ {
+		f.AddInt(i)
+	}
+	h := f.Health(1e-2)
+	if h.Overloaded || h.RecommendedM != m || h.RecommendedK != k {
+		t.Log(h)
+		t.Fail()
+	}
+	for i := 500; i < 5000; i++ {
+		f.AddInt(i)
+	}
+	h = f.Health(1e-2)
+	if !h.Overloaded || h.RecommendedM <= m || h.FillRatio <= 0.5 {
+		t.Log(h)
+		t.Fail()
+	}
+}
+
+func TestHealthTarget(t *testing.T) {
+	m, k := EstimateParameters(1000, 1e-2)
+	f := New(m, k)
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	// the filter is right for 1%, but overloaded for 0.1%
+	if f.Health(1e-2).Overloaded {
+		t.Fail()
+	}
+	h := f.Health(1e-3)
+	if wm, wk := EstimateParameters(int(f.ApproximateCount()), 1e-3); !h.Overloaded || h.RecommendedM != wm || h.RecommendedK != wk {
+		t.Log(h, wm, wk)
+		t.Fail()
+	}
+}
+
+func TestHealthFull(t *testing.T) {
+	f := New(32, 2)
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	h := f.Health(0)
+	if !h.Overloaded || h.FillRatio != 1 || h.RecommendedM <= 32 {
+		t.Log(h)
+		t.Fail()
+	}
+}
+
+func TestIsSaturated(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	f := New(m, k)
+	for i := 0; i < 900; i++ {
+		f.AddInt(i)
+	}
+	if f.IsSaturated(0) || f.IsSaturated(0.9) {
+		t.Fail()
+	}
+	for i := 900; i < 2000; i++ {
+		f.AddInt(i)
+	}
+	if !f.IsSaturated(0) || f.IsSaturated(0.9) {
+		t.Fail()
+	}
+	for i := 2000; i < 10000; i++ {
+		f.AddInt(i)
+	}
+	if !f.IsSaturated(0.9) || New(0, 1).IsSaturated(0) {
+		t.Fail()
+	}
+}
+
+func TestAutoTuneReport(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	good := New(m, k)
+	small := New(m/4, k)
+	wrongK := New(m, 2)
+	for i := 0; i < 1000; i++ {
+		good.AddInt(i)
+		small.AddInt(i)
+		wrongK.AddInt(i)
+	}
+	if r := good.AutoTuneReport(0.01); r.Rebuild || r.OptimalK != k {
+		t.Log(r)
+		t.Fail()
+	}
+	r := small.AutoTuneReport(0.01)
+	if !r.Rebuild || r.OptimalK != k || r.OptimalM < m*9/10 || r.OptimalM > m*11/10 || r.FPRate <= 0.01 {
+		t.Log(r)
+		t.Fail()
+	}
+	r = wrongK.AutoTuneReport(0.01)
+	if !r.Rebuild || r.OptimalK != k || r.EstimatedN < 950 || r.EstimatedN > 1050 {
+		t.Log(r)
+		t.Fail()
+	}
+	if r := New(1000, 4).AutoTuneReport(0.01); r.Rebuild || r.OptimalM != 1024 {
+		t.Log(r)
+		t.Fail()
+	}
+}
+
+func TestAutoTuneReportEdgeCases(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	f := New(m, k)
+	for i := 0; i < 100; i++ {
+		f.AddInt(i)
+	}
+	for _, p := range []float64{0, -1, 1, 1.5} {
+		r := f.AutoTuneReport(p)
+		if r.OptimalM <= 0 || r.OptimalK <= 0 {
+			t.Log(p, r)
+			t.Fail()
+		}
+	}
+	// a single bit set estimates less than half an item
+	buckets := make([]uint32, 1000)
+	buckets[0] = 1
+	r := NewFromBuckets(buckets, 4).AutoTuneReport(0.01)
+	if r.EstimatedN != 0 || r.OptimalM <= 0 || r.OptimalK <= 0 {
+		t.Log(r)
+		t.Fail()
+	}
+	if n, m, k := sizeFor(1, 32000, 4, 0.01); n != 0 || m <= 0 || k <= 0 {
+		t.Log(n, m, k)
+		t.Fail()
+	}
+}
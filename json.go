@@ -0,0 +1,46 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// AddJSON adds the canonical JSON encoding of v to the bloom filter.
+// Object keys are sorted at every level, so values that differ only in key
+// order, such as {"a":1,"b":2} and {"b":2,"a":1}, are the same item.
+// Pass a json.RawMessage to add a JSON document that is already encoded.
+func (bf *BloomFilter) AddJSON(v interface{}) error {
+	bb, err := canonicalJSON(v)
+	if err != nil {
+		return err
+	}
+	bf.Add(bb)
+	return nil
+}
+
+// TestJSON evaluates the canonical JSON encoding of v to determine whether it is
+// (probably) in the bloom filter. See AddJSON.
+func (bf *BloomFilter) TestJSON(v interface{}) (bool, error) {
+	bb, err := canonicalJSON(v)
+	if err != nil {
+		return false, err
+	}
+	return bf.Test(bb), nil
+}
+
+// canonicalJSON encodes v, decodes it into generic values and encodes it again,
+// which sorts all object keys and normalizes whitespace. Numbers keep their
+// original text.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var d = json.NewDecoder(bytes.NewReader(bb))
+	d.UseNumber()
+	var g interface{}
+	if err := d.Decode(&g); err != nil {
+		return nil, err
+	}
+	return json.Marshal(g)
+}
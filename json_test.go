@@ -0,0 +1,50 @@
+package bloomfilter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	f := New(1000, 4)
+	if err := f.AddJSON(json.RawMessage(`{"a":1,"b":{"d":[1,2],"c":"x"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []interface{}{
+		json.RawMessage(`{"b": {"c": "x", "d": [1, 2]}, "a": 1}`),
+		map[string]interface{}{"b": map[string]interface{}{"c": "x", "d": []int{1, 2}}, "a": 1},
+		struct {
+			B struct {
+				D []int  `json:"d"`
+				C string `json:"c"`
+			} `json:"b"`
+			A int `json:"a"`
+		}{A: 1, B: struct {
+			D []int  `json:"d"`
+			C string `json:"c"`
+		}{D: []int{1, 2}, C: "x"}},
+	} {
+		ok, err := f.TestJSON(v)
+		if err != nil || !ok {
+			t.Log(v, err)
+			t.Fail()
+		}
+	}
+	for _, v := range []interface{}{
+		json.RawMessage(`{"a":1,"b":{"d":[2,1],"c":"x"}}`),
+		json.RawMessage(`{"a":2,"b":{"d":[1,2],"c":"x"}}`),
+		map[string]int{"a": 1},
+	} {
+		ok, err := f.TestJSON(v)
+		if err != nil || ok {
+			t.Log(v, err)
+			t.Fail()
+		}
+	}
+	if err := f.AddJSON(func() {}); err == nil {
+		t.Fail()
+	}
+	if _, err := f.TestJSON(json.RawMessage(`{`)); err == nil {
+		t.Fail()
+	}
+}
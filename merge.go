@@ -0,0 +1,46 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+)
+
+// MergeReaders creates a new bloom filter by ORing together filters read from readers.
+// Each reader should supply a byte slice exported with ToBytes.
+// The first reader establishes m; every other reader is streamed word by word
+// into the result, so only one filter is held in memory at a time.
+// k specifies the number of hashing functions.
+func MergeReaders(k int, readers ...io.Reader) (*BloomFilter, error) {
+	if len(readers) == 0 {
+		return nil, ErrEmpty
+	}
+	bb, err := ioutil.ReadAll(readers[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(bb)%4 != 0 {
+		return nil, ErrSizeMismatch
+	}
+	var bf = NewFromBytes(bb, k)
+	var word = make([]byte, 4)
+	for _, r := range readers[1:] {
+		var br = bufio.NewReader(r)
+		for i := range bf.buckets {
+			if _, err := io.ReadFull(br, word); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil, ErrSizeMismatch
+				}
+				return nil, err
+			}
+			bf.buckets[i] |= binary.BigEndian.Uint32(word)
+		}
+		if _, err := br.ReadByte(); err == nil {
+			return nil, ErrSizeMismatch
+		} else if err != io.EOF {
+			return nil, err
+		}
+	}
+	return bf, nil
+}
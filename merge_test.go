@@ -0,0 +1,49 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestMergeReaders(t *testing.T) {
+	k := 4
+	m := 1000
+	f1 := New(m, k)
+	f1.Add([]byte("abc"))
+	f2 := New(m, k)
+	f2.Add([]byte("def"))
+	f3 := New(m, k)
+	f3.Add([]byte("ghi"))
+	f, err := MergeReaders(k,
+		bytes.NewReader(f1.ToBytes()),
+		bytes.NewReader(f2.ToBytes()),
+		bytes.NewReader(f3.ToBytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"abc", "def", "ghi"} {
+		if !f.Test([]byte(v)) {
+			t.Log(v)
+			t.Fail()
+		}
+	}
+	if f.Test([]byte("jkl")) {
+		t.Fail()
+	}
+}
+
+func TestMergeReadersSizeMismatch(t *testing.T) {
+	k := 4
+	f1 := New(1000, k)
+	f2 := New(2000, k)
+	for _, rr := range [][]io.Reader{
+		{bytes.NewReader(f1.ToBytes()), bytes.NewReader(f2.ToBytes())},
+		{bytes.NewReader(f2.ToBytes()), bytes.NewReader(f1.ToBytes())},
+	} {
+		if _, err := MergeReaders(k, rr...); err != ErrSizeMismatch {
+			t.Log(err)
+			t.Fail()
+		}
+	}
+}
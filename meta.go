@@ -0,0 +1,48 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// WriteWithMeta writes the bloom filter to w in the aligned format followed by
+// meta, an opaque application metadata blob such as a creation time or source
+// name. The length of meta is stored in the reserved header field, so readers
+// of the aligned format that do not care about it, such as NewFromMmap and
+// UnmarshalBinary, ignore it.
+func (bf *BloomFilter) WriteWithMeta(w io.Writer, meta []byte) (int64, error) {
+	if uint64(len(meta)) > 1<<32-1 {
+		return 0, errors.New("bloomfilter: metadata too long")
+	}
+	var bb = bf.ToAlignedBytes()
+	binary.BigEndian.PutUint32(bb[20:], uint32(len(meta)))
+	n, err := w.Write(bb)
+	if err != nil {
+		return int64(n), err
+	}
+	nm, err := w.Write(meta)
+	return int64(n + nm), err
+}
+
+// ReadWithMeta reads a bloom filter and its metadata written by WriteWithMeta
+// from r. A filter in the aligned format without metadata has empty metadata.
+func ReadWithMeta(r io.Reader) (*BloomFilter, []byte, error) {
+	header, h, err := readAlignedHeader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var metaSize = int64(binary.BigEndian.Uint32(header[20:]))
+	var buf bytes.Buffer
+	buf.Write(header)
+	if err := readN(&buf, r, int64(alignedSize(int(h.m/32))-alignedHeaderSize)+metaSize); err != nil {
+		return nil, nil, err
+	}
+	var bb = buf.Bytes()
+	var bf = &BloomFilter{}
+	if err := bf.UnmarshalBinary(bb); err != nil {
+		return nil, nil, err
+	}
+	return bf, bb[int64(len(bb))-metaSize:], nil
+}
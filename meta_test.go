@@ -0,0 +1,37 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWriteWithMeta(t *testing.T) {
+	f := NewEnhanced(1000, 4)
+	f.Add([]byte("abc"))
+	meta := []byte("created=2024-01-01 source=users")
+	var buf bytes.Buffer
+	n, err := f.WriteWithMeta(&buf, meta)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatal(n, err)
+	}
+	g, gm, err := ReadWithMeta(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gm, meta) || !g.Compatible(f) || !bytes.Equal(g.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+	mf, err := NewFromMmap(buf.Bytes())
+	if err != nil || !mf.Test([]byte("abc")) {
+		t.Log("metadata not ignored by NewFromMmap")
+		t.Fail()
+	}
+	g, gm, err = ReadWithMeta(bytes.NewReader(f.ToAlignedBytes()))
+	if err != nil || len(gm) != 0 || !g.Test([]byte("abc")) {
+		t.Fail()
+	}
+	if _, _, err := ReadWithMeta(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err != io.ErrUnexpectedEOF {
+		t.Fail()
+	}
+}
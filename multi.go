@@ -0,0 +1,58 @@
+package bloomfilter
+
+import (
+	"math"
+	"sync"
+)
+
+// MultiFilter holds many small bloom filters of the same m and k in one
+// contiguous backing array, one partition per tenant, which saves the
+// allocation and slice header of a separate filter per tenant.
+type MultiFilter struct {
+	m       uint32
+	k       int
+	n       uint32
+	buckets []uint32
+	lock    sync.RWMutex
+}
+
+// NewMulti creates a multi filter of tenants bloom filters. m should specify the
+// number of bits in each and is rounded up to the nearest multiple of 32.
+// k specifies the number of hashing functions.
+func NewMulti(tenants, m, k int) *MultiFilter {
+	var n = uint32(math.Ceil(float64(m) / 32))
+	return &MultiFilter{
+		m:       n * 32,
+		k:       k,
+		n:       n,
+		buckets: make([]uint32, uint32(tenants)*n),
+	}
+}
+
+// partition returns the buckets of tenant, panicking if it is out of range
+func (mf *MultiFilter) partition(tenant int) []uint32 {
+	return mf.buckets[uint32(tenant)*mf.n : uint32(tenant+1)*mf.n]
+}
+
+// Add adds a byte array to the bloom filter of tenant
+func (mf *MultiFilter) Add(tenant int, v []byte) {
+	mf.lock.Lock()
+	defer mf.lock.Unlock()
+	var buckets = mf.partition(tenant)
+	for _, l := range locations(v, mf.m, mf.k) {
+		buckets[l/32] |= 1 << (l % 32)
+	}
+}
+
+// Test evaluates a byte array to determine whether it is (probably) in the bloom filter of tenant
+func (mf *MultiFilter) Test(tenant int, v []byte) bool {
+	mf.lock.RLock()
+	defer mf.lock.RUnlock()
+	var buckets = mf.partition(tenant)
+	for _, l := range locations(v, mf.m, mf.k) {
+		if (buckets[l/32] & (1 << (l % 32))) == 0 {
+			return false
+		}
+	}
+	return true
+}
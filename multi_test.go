@@ -0,0 +1,41 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestMultiFilter(t *testing.T) {
+	mf := NewMulti(100, 1000, 4)
+	for tenant := 0; tenant < 100; tenant += 2 {
+		for i := 0; i < 50; i++ {
+			mf.Add(tenant, []byte{byte(tenant), byte(i)})
+		}
+	}
+	for tenant := 0; tenant < 100; tenant++ {
+		for i := 0; i < 50; i++ {
+			if mf.Test(tenant, []byte{byte(tenant), byte(i)}) != (tenant%2 == 0) {
+				t.Log(tenant, i)
+				t.Fail()
+			}
+		}
+	}
+	one := New(1000, 4)
+	for i := 0; i < 50; i++ {
+		one.Add([]byte{0, byte(i)})
+	}
+	for i := 0; i < 256; i++ {
+		if mf.Test(0, []byte{0, byte(i)}) != one.Test([]byte{0, byte(i)}) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+}
+
+func TestMultiFilterTenantRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+	NewMulti(2, 1000, 4).Test(2, []byte("abc"))
+}
@@ -0,0 +1,26 @@
+package bloomfilter
+
+// NegativeCache wraps a bloom filter with names that make its one-sided error
+// explicit. Keys are marked as seen; a key that was never marked may still be
+// reported as seen (a false positive), but a marked key is never reported as new.
+type NegativeCache struct {
+	bf *BloomFilter
+}
+
+// NewNegativeCache creates a new negative cache backed by a bloom filter of m bits
+// and k hashing functions.
+func NewNegativeCache(m, k int) *NegativeCache {
+	return &NegativeCache{bf: New(m, k)}
+}
+
+// MarkSeen records that key has been seen
+func (nc *NegativeCache) MarkSeen(key []byte) {
+	nc.bf.Add(key)
+}
+
+// MightBeNew reports whether key has never been marked seen.
+// A true result is certain. A false result means key was probably seen,
+// but may be a false positive for a new key.
+func (nc *NegativeCache) MightBeNew(key []byte) bool {
+	return !nc.bf.Test(key)
+}
@@ -0,0 +1,31 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestNegativeCache(t *testing.T) {
+	nc := NewNegativeCache(1000, 4)
+	if !nc.MightBeNew([]byte("abc")) {
+		t.Fail()
+	}
+	nc.MarkSeen([]byte("abc"))
+	if nc.MightBeNew([]byte("abc")) {
+		t.Fail()
+	}
+	if !nc.MightBeNew([]byte("def")) {
+		t.Fail()
+	}
+}
+
+func TestNegativeCacheNeverForgets(t *testing.T) {
+	nc := NewNegativeCache(64, 2)
+	for i := 0; i < 1000; i++ {
+		nc.MarkSeen([]byte{byte(i), byte(i >> 8)})
+	}
+	for i := 0; i < 1000; i++ {
+		if nc.MightBeNew([]byte{byte(i), byte(i >> 8)}) {
+			t.Fatal(i)
+		}
+	}
+}
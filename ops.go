@@ -0,0 +1,256 @@
+package bloomfilter
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+	"unsafe"
+)
+
+// SymmetricDifference returns a new bloom filter whose buckets are the XOR of
+// the buckets of bf and other, which must have the same m, k and hashing scheme.
+// This is a bit-level operation: it only approximates the symmetric difference
+// of the underlying sets, since bits shared by items in both sets cancel out.
+func (bf *BloomFilter) SymmetricDifference(other *BloomFilter) (*BloomFilter, error) {
+	if err := bf.checkCompatible(other); err != nil {
+		return nil, err
+	}
+	var a = bf.snapshot()
+	var b = other.snapshot()
+	for i := range a {
+		a[i] ^= b[i]
+	}
+	return bf.withBuckets(a), nil
+}
+
+// Compatible reports whether bf and other have the same m, k and hashing scheme,
+// which is required to combine or compare them.
+func (bf *BloomFilter) Compatible(other *BloomFilter) bool {
+	return bf.checkCompatible(other) == nil
+}
+
+// checkCompatible returns ErrSizeMismatch, ErrKMismatch or ErrHashMismatch for
+// the first difference between bf and other, or nil if they are Compatible.
+// Each filter's parameters are read under its own read lock, so bf may be other.
+func (bf *BloomFilter) checkCompatible(other *BloomFilter) error {
+	var m, k, h = bf.params()
+	var otherM, otherK, otherH = other.params()
+	switch {
+	case m != otherM:
+		return ErrSizeMismatch
+	case k != otherK:
+		return ErrKMismatch
+	case !h.equal(otherH):
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// withBuckets returns a new bloom filter with the parameters of bf and the given buckets
+func (bf *BloomFilter) withBuckets(buckets []uint32) *BloomFilter {
+	return &BloomFilter{
+		m:       bf.m,
+		k:       bf.k,
+		hashing: bf.hashing,
+		order:   bf.order,
+		buckets: buckets,
+		lock:    &sync.RWMutex{},
+	}
+}
+
+// Clone returns an independent copy of the bloom filter with the same
+// configuration and contents. Statistics start at zero. The read lock is held
+// only while the buckets are copied, so writers are delayed as little as possible.
+func (bf *BloomFilter) Clone() *BloomFilter {
+	var buckets = bf.lockedSnapshot()
+	var c = &BloomFilter{
+		stats:      bf.stats,
+		hashing:    bf.hashing,
+		sink:       bf.sink,
+		exactCount: bf.exactCount,
+		count:      bf.count,
+		order:      bf.order,
+		m:          bf.m,
+		k:          bf.k,
+		buckets:    buckets,
+		overrides:  copyOverrides(bf.overrides),
+	}
+	var _, unlocked = bf.lock.(noLock)
+	bf.lock.RUnlock()
+	if unlocked {
+		c.lock = noLock{}
+	} else {
+		c.lock = &sync.RWMutex{}
+	}
+	return c
+}
+
+// SnapshotUnion returns a new bloom filter that is the union of bf and others,
+// which must all have the same m, k and hashing scheme. Each input is copied
+// under its own read lock before merging, so inputs may keep being written
+// while the union is computed. Writes that race with the copy may be missed.
+func (bf *BloomFilter) SnapshotUnion(others ...*BloomFilter) (*BloomFilter, error) {
+	for _, other := range others {
+		if err := bf.checkCompatible(other); err != nil {
+			return nil, err
+		}
+	}
+	var a = bf.snapshot()
+	for _, other := range others {
+		for i, bucket := range other.snapshot() {
+			a[i] |= bucket
+		}
+	}
+	return bf.withBuckets(a), nil
+}
+
+// UnionWithReport returns the union of bf and others like SnapshotUnion, together
+// with the estimated false positive rate of the result given its fill.
+// A merge with a nearly full filter yields a high rate.
+func (bf *BloomFilter) UnionWithReport(others ...*BloomFilter) (*BloomFilter, float64, error) {
+	u, err := bf.SnapshotUnion(others...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return u, u.EstimatedFPRate(), nil
+}
+
+// Transcode returns a new bloom filter with the same m and k as bf that hashes
+// with newHasher, holding elements. A bloom filter cannot be rehashed in place,
+// so elements must be the original keys added to bf.
+func (bf *BloomFilter) Transcode(elements [][]byte, newHasher Hasher) *BloomFilter {
+	var t = bf.withBuckets(make([]uint32, len(bf.buckets)))
+	t.hashing.hasher = newHasher
+	for _, v := range elements {
+		t.add(v)
+	}
+	return t
+}
+
+// UnionGrowing returns the union of filters, which must all be Compatible, unless
+// the result would have a false positive rate above p. The elements of a bloom
+// filter cannot be recovered to grow it, so in that case UnionGrowing returns
+// an error advising a rebuild sized for elementsHint items instead.
+func UnionGrowing(p float64, elementsHint int, filters ...*BloomFilter) (*BloomFilter, error) {
+	if len(filters) == 0 {
+		return nil, ErrEmpty
+	}
+	u, fp, err := filters[0].UnionWithReport(filters[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	if fp > p {
+		var m, k = EstimateParameters(elementsHint, p)
+		return nil, fmt.Errorf("bloomfilter: union false positive rate %g exceeds %g; rebuild with m=%d k=%d", fp, p, m, k)
+	}
+	return u, nil
+}
+
+// OrInto sets every bit of bf in dst, which must be Compatible with bf, without
+// allocating. It is the in-place counterpart to SnapshotUnion.
+func (bf *BloomFilter) OrInto(dst *BloomFilter) error {
+	if err := bf.checkCompatible(dst); err != nil {
+		return err
+	}
+	if bf == dst {
+		return nil
+	}
+	// Take the locks in address order so concurrent a.OrInto(b) and
+	// b.OrInto(a) cannot deadlock.
+	if uintptr(unsafe.Pointer(bf)) < uintptr(unsafe.Pointer(dst)) {
+		bf.lock.RLock()
+		dst.lock.Lock()
+	} else {
+		dst.lock.Lock()
+		bf.lock.RLock()
+	}
+	defer bf.lock.RUnlock()
+	defer dst.lock.Unlock()
+	for i, bucket := range bf.buckets {
+		dst.buckets[i] |= bucket
+	}
+	return nil
+}
+
+// TestQuorum reports whether at least quorum of filters test positive for v, so
+// a membership decision across replicas tolerates a stale one. Each filter tests
+// v independently, so they need not be Compatible, though replicas normally are.
+func TestQuorum(v []byte, quorum int, filters ...*BloomFilter) bool {
+	var n = 0
+	for i, bf := range filters {
+		if n >= quorum || n+len(filters)-i < quorum {
+			break
+		}
+		if bf.Test(v) {
+			n++
+		}
+	}
+	return n >= quorum
+}
+
+// MajorityVote returns a new bloom filter with a bit set wherever at least
+// threshold of filters have it set. filters must all be Compatible. A threshold
+// of 1 is the union and len(filters) the intersection; in between, bits set by
+// false positives or stray writes in a minority of the inputs are dropped.
+func MajorityVote(threshold int, filters ...*BloomFilter) (*BloomFilter, error) {
+	if len(filters) == 0 {
+		return nil, ErrEmpty
+	}
+	if threshold < 1 {
+		return nil, errors.New("bloomfilter: threshold must be positive")
+	}
+	for _, other := range filters[1:] {
+		if err := filters[0].checkCompatible(other); err != nil {
+			return nil, err
+		}
+	}
+	var snapshots = make([][]uint32, len(filters))
+	for i, bf := range filters {
+		snapshots[i] = bf.snapshot()
+		if len(snapshots[i]) != len(snapshots[0]) {
+			return nil, ErrSizeMismatch
+		}
+	}
+	var r = filters[0].withBuckets(make([]uint32, len(snapshots[0])))
+	// votes are counted one bucket at a time, so only 32 counters are needed
+	for i := range r.buckets {
+		var votes [32]int
+		for _, buckets := range snapshots {
+			for bucket := buckets[i]; bucket != 0; bucket &= bucket - 1 {
+				votes[bits.TrailingZeros32(bucket)]++
+			}
+		}
+		for b, n := range votes {
+			if n >= threshold {
+				r.buckets[i] |= 1 << uint(b)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Downsample returns a new bloom filter 1/factor the size of bf, with bit l of
+// bf set as bit l mod m/factor, so every value that tests positive in bf still
+// does, at a higher false positive rate. factor must be a power of two that
+// divides the number of buckets, and m/factor must be a power of two, as with
+// NewPow2, since only then does folding preserve each value's bit indexes.
+func (bf *BloomFilter) Downsample(factor int) (*BloomFilter, error) {
+	if factor < 1 || factor&(factor-1) != 0 {
+		return nil, errors.New("bloomfilter: factor must be a power of two")
+	}
+	var buckets = bf.snapshot()
+	if len(buckets)%factor != 0 {
+		return nil, errors.New("bloomfilter: factor does not divide the number of buckets")
+	}
+	var n = len(buckets) / factor
+	if n&(n-1) != 0 {
+		return nil, errors.New("bloomfilter: downsampled m is not a power of two")
+	}
+	var r = bf.withBuckets(make([]uint32, n))
+	r.m = uint32(n * 32)
+	for i, bucket := range buckets {
+		r.buckets[i%n] |= bucket
+	}
+	return r, nil
+}
@@ -0,0 +1,443 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSymmetricDifference(t *testing.T) {
+	f1 := New(1000, 4)
+	f2 := New(1000, 4)
+	for i := 0; i < 100; i++ {
+		f1.AddInt(i)
+		f2.AddInt(i + 50)
+	}
+	d, err := f1.SymmetricDifference(f2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hamming := 0
+	for i := range f1.buckets {
+		hamming += bits.OnesCount32(f1.buckets[i] ^ f2.buckets[i])
+	}
+	if d.PopCount() != hamming {
+		t.Log(d.PopCount(), hamming)
+		t.Fail()
+	}
+	if _, err := f1.SymmetricDifference(New(2000, 4)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+	if _, err := f1.SymmetricDifference(NewEnhanced(1000, 4)); !errors.Is(err, ErrHashMismatch) {
+		t.Fail()
+	}
+}
+
+func TestSnapshotUnion(t *testing.T) {
+	ff := []*BloomFilter{New(1000, 4), New(1000, 4), New(1000, 4)}
+	for i, f := range ff {
+		f.AddInt(i)
+	}
+	u, err := ff[0].SnapshotUnion(ff[1:]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range ff {
+		if !u.TestInt(i) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+	if u.TestInt(3) {
+		t.Fail()
+	}
+	if _, err := ff[0].SnapshotUnion(ff[1], New(2000, 4)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+}
+
+func TestSnapshotUnionConcurrentWrites(t *testing.T) {
+	ff := []*BloomFilter{New(100000, 4), New(100000, 4), New(100000, 4)}
+	var wg sync.WaitGroup
+	for j, f := range ff {
+		wg.Add(1)
+		go func(j int, f *BloomFilter) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				f.AddInt(j*1000 + i)
+			}
+		}(j, f)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := ff[0].SnapshotUnion(ff[1:]...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	u, err := ff[0].SnapshotUnion(ff[1:]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3000; i++ {
+		if !u.TestInt(i) {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	f := New(1000, 4)
+	if !f.Compatible(New(1000, 4)) || !f.Compatible(NewFromBytes(f.ToBytes(), 4)) {
+		t.Fail()
+	}
+	if f.Compatible(New(2000, 4)) || f.Compatible(New(1000, 5)) {
+		t.Fail()
+	}
+	if f.Compatible(NewEnhanced(1000, 4)) || !NewEnhanced(1000, 4).Compatible(NewEnhanced(1000, 4)) {
+		t.Fail()
+	}
+}
+
+func TestUnionWithReport(t *testing.T) {
+	m, k := EstimateParameters(1000, 1e-3)
+	sparse := New(m, k)
+	full := New(m, k)
+	for i := 0; i < 100; i++ {
+		sparse.AddInt(i)
+	}
+	for i := 0; i < 10000; i++ {
+		full.AddInt(i)
+	}
+	_, p, err := sparse.UnionWithReport(New(m, k))
+	if err != nil || p > 1e-6 {
+		t.Log(p, err)
+		t.Fail()
+	}
+	u, p, err := sparse.UnionWithReport(full)
+	if err != nil || p < 0.5 {
+		t.Log(p, err)
+		t.Fail()
+	}
+	if p != u.EstimatedFPRate() {
+		t.Fail()
+	}
+	if _, _, err := sparse.UnionWithReport(New(m+32, k)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	elements := [][]byte{[]byte("abc"), []byte("def"), []byte("ghi")}
+	f := New(1000, 4)
+	f.AddBatch(elements)
+	h := xorHasher(14695981039346656037)
+	tf := f.Transcode(elements, h)
+	if tf.m != f.m || tf.k != f.k || tf.hashing.hasher != Hasher(h) {
+		t.Fail()
+	}
+	if !tf.Compatible(NewWithOptions(1000, 4, WithHasher(h))) {
+		t.Fail()
+	}
+	for _, v := range elements {
+		if !tf.Test(v) {
+			t.Log(string(v))
+			t.Fail()
+		}
+	}
+	if tf.Test([]byte("jkl")) || bytes.Equal(tf.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+}
+
+func TestUnionGrowing(t *testing.T) {
+	m, k := EstimateParameters(1000, 1e-2)
+	ff := make([]*BloomFilter, 4)
+	for j := range ff {
+		ff[j] = New(m, k)
+		for i := 0; i < 500; i++ {
+			ff[j].AddInt(j*500 + i)
+		}
+	}
+	u, err := UnionGrowing(1e-2, 1000, ff[:2]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if !u.TestInt(i) {
+			t.Fatal(i)
+		}
+	}
+	if _, err := UnionGrowing(1e-2, 2000, ff...); err == nil {
+		t.Fail()
+	} else {
+		t.Log(err)
+	}
+	if _, err := UnionGrowing(1e-2, 1000); err == nil {
+		t.Fail()
+	}
+}
+
+func TestOrInto(t *testing.T) {
+	dst := New(1000, 4)
+	for i := 0; i < 5; i++ {
+		f := New(1000, 4)
+		f.AddInt(i)
+		if err := f.OrInto(dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		if !dst.TestInt(i) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+	if dst.TestInt(5) {
+		t.Fail()
+	}
+	if err := dst.OrInto(dst); err != nil {
+		t.Fail()
+	}
+	if err := New(2000, 4).OrInto(dst); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+}
+
+func TestOrIntoConcurrent(t *testing.T) {
+	a := New(1000, 4)
+	b := New(1000, 4)
+	var wg sync.WaitGroup
+	for _, pair := range [][2]*BloomFilter{{a, b}, {b, a}} {
+		wg.Add(1)
+		go func(src, dst *BloomFilter) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				src.OrInto(dst)
+			}
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+}
+
+func BenchmarkOrInto(b *testing.B) {
+	m, k := EstimateParameters(100000, 1e-4)
+	src := New(m, k)
+	dst := New(m, k)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		src.OrInto(dst)
+	}
+}
+
+func TestMismatchErrors(t *testing.T) {
+	f := New(1000, 4)
+	for _, c := range []struct {
+		other *BloomFilter
+		err   error
+	}{
+		{New(2000, 4), ErrSizeMismatch},
+		{New(1000, 5), ErrKMismatch},
+		{NewEnhanced(1000, 4), ErrHashMismatch},
+		{NewWithOptions(1000, 4, WithSeed(1)), ErrHashMismatch},
+	} {
+		_, err1 := f.SnapshotUnion(c.other)
+		_, err2 := f.SymmetricDifference(c.other)
+		_, err3 := ApproxAddedBetween(f, c.other)
+		err4 := f.OrInto(c.other)
+		for _, err := range []error{err1, err2, err3, err4} {
+			if !errors.Is(err, c.err) || !errors.Is(err, ErrIncompatible) {
+				t.Log(c.err, err)
+				t.Fail()
+			}
+		}
+	}
+	if _, err := CountingUnion(NewCounting(1000, 4, 8), NewCounting(1000, 5, 8)); !errors.Is(err, ErrKMismatch) {
+		t.Fail()
+	}
+	_, err1 := UnionGrowing(1e-2, 1000)
+	_, err2 := MergeReaders(4)
+	_, err3 := CountingUnion()
+	for _, err := range []error{err1, err2, err3} {
+		if !errors.Is(err, ErrEmpty) || errors.Is(err, ErrIncompatible) {
+			t.Log(err)
+			t.Fail()
+		}
+	}
+	if errors.Is(ErrSizeMismatch, ErrKMismatch) {
+		t.Fail()
+	}
+}
+
+func TestClone(t *testing.T) {
+	f := NewWithOptions(1000, 4, WithSeed(3), WithExactCount())
+	f.Add([]byte("abc"))
+	c := f.Clone()
+	if !bytes.Equal(c.ToBytes(), f.ToBytes()) || !c.Compatible(f) || c.Count() != 1 {
+		t.Fail()
+	}
+	c.Add([]byte("def"))
+	if f.Test([]byte("def")) || f.Count() != 1 {
+		t.Log("clone shares state with original")
+		t.Fail()
+	}
+}
+
+func TestCloneDuringAdds(t *testing.T) {
+	f := New(100000, 4)
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1000; i < 20000; i++ {
+			f.AddInt(i)
+		}
+	}()
+	for j := 0; j < 20; j++ {
+		c := f.Clone()
+		for i := 0; i < 1000; i++ {
+			if !c.TestInt(i) {
+				t.Fatal(i)
+			}
+		}
+	}
+	wg.Wait()
+}
+
+// heldLock is a locker that records how long its read lock is held
+type heldLock struct {
+	sync.RWMutex
+	since time.Time
+	held  time.Duration
+}
+
+func (l *heldLock) RLock() {
+	l.RWMutex.RLock()
+	l.since = time.Now()
+}
+
+func (l *heldLock) RUnlock() {
+	l.held += time.Since(l.since)
+	l.RWMutex.RUnlock()
+}
+
+func BenchmarkCloneLockHold(b *testing.B) {
+	m, k := EstimateParameters(1000000, 1e-4)
+	f := New(m, k)
+	l := &heldLock{}
+	f.lock = l
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Clone()
+	}
+	b.ReportMetric(float64(l.held.Nanoseconds())/float64(b.N), "ns-held/op")
+}
+
+func TestTestQuorum(t *testing.T) {
+	ff := []*BloomFilter{New(1000, 4), New(1000, 4), New(1000, 4)}
+	v := []byte("abc")
+	ff[0].Add(v)
+	ff[2].Add(v)
+	for quorum, want := range []bool{true, true, true, false} {
+		if TestQuorum(v, quorum, ff...) != want {
+			t.Log(quorum)
+			t.Fail()
+		}
+	}
+	if TestQuorum([]byte("def"), 1, ff...) || !TestQuorum(v, 0) || TestQuorum(v, 1) {
+		t.Fail()
+	}
+}
+
+func TestMajorityVote(t *testing.T) {
+	ff := []*BloomFilter{New(1000, 4), New(1000, 4), New(1000, 4)}
+	for _, f := range ff {
+		f.Add([]byte("common"))
+	}
+	ff[0].Add([]byte("stray"))
+	ff[1].Add([]byte("pair"))
+	ff[2].Add([]byte("pair"))
+	m, err := MajorityVote(2, ff...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Test([]byte("common")) || !m.Test([]byte("pair")) || m.Test([]byte("stray")) {
+		t.Fail()
+	}
+	all, _ := MajorityVote(3, ff...)
+	one, _ := MajorityVote(1, ff...)
+	u, _ := ff[0].SnapshotUnion(ff[1:]...)
+	if all.Test([]byte("pair")) || !all.Test([]byte("common")) || !bytes.Equal(one.ToBytes(), u.ToBytes()) {
+		t.Fail()
+	}
+	if _, err := MajorityVote(2, ff[0], New(2000, 4)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+	if _, err := MajorityVote(2); err != ErrEmpty {
+		t.Fail()
+	}
+	if _, err := MajorityVote(0, ff...); err == nil {
+		t.Fail()
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	for _, f := range []*BloomFilter{NewPow2(16, 4), NewEnhanced(1<<16, 4), NewWithOptions(1<<16, 4, WithSummaryBit())} {
+		for i := 0; i < 1000; i++ {
+			f.AddInt(i)
+		}
+		d, err := f.Downsample(8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.M() != 1<<13 || d.K() != 4 {
+			t.Fatal(d.M())
+		}
+		for i := 0; i < 1000; i++ {
+			if !d.TestInt(i) {
+				t.Fatal(i)
+			}
+		}
+		if d.EstimatedFPRate() <= f.EstimatedFPRate() {
+			t.Fail()
+		}
+	}
+	if _, err := NewPow2(16, 4).Downsample(3); err == nil {
+		t.Fail()
+	}
+	if _, err := NewPow2(6, 4).Downsample(4); err == nil {
+		t.Fail()
+	}
+	if _, err := New(96, 4).Downsample(1); err == nil {
+		t.Fail()
+	}
+}
+
+func TestCompatibleConcurrentReset(t *testing.T) {
+	a, b := New(1000, 4), New(1000, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for seed := uint64(0); seed < 1000; seed++ {
+			a.ResetWithSeed(seed % 2)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		// run under -race: parameters must be read under the lock
+		a.Compatible(b)
+		a.ApproximateCount()
+		a.EstimatedFPRate()
+		a.ClearRange(0, 32)
+	}
+}
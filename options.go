@@ -0,0 +1,229 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+)
+
+// Option configures a bloom filter created with NewWithOptions.
+type Option func(*BloomFilter)
+
+// NewWithOptions creates a new bloom filter like New and applies opts to it.
+func NewWithOptions(m, k int, opts ...Option) *BloomFilter {
+	var bf = New(m, k)
+	for _, opt := range opts {
+		opt(bf)
+	}
+	return bf
+}
+
+// NewChecked creates a new bloom filter like NewWithOptions, but returns an error
+// if m or k is not positive, or ErrDegenerateHash if the configured seed or
+// hasher makes the two base hashes equal.
+// Individual keys whose second base hash is a multiple of m still map to a
+// single bit under plain double hashing; NewEnhanced avoids this.
+func NewChecked(m, k int, opts ...Option) (*BloomFilter, error) {
+	if m <= 0 || k <= 0 {
+		return nil, errors.New("bloomfilter: m and k must be positive")
+	}
+	var bf = NewWithOptions(m, k, opts...)
+	if err := bf.hashing.check(); err != nil {
+		return nil, err
+	}
+	return bf, nil
+}
+
+// WithKeySink calls fn with a copy of every key added to the bloom filter, so
+// added keys can be logged for audit or debugging. fn is called while the
+// filter's lock is held and must not call back into the filter.
+// Keys added with AddHashedStream are not passed to fn.
+func WithKeySink(fn func([]byte)) Option {
+	return func(bf *BloomFilter) {
+		bf.sink = fn
+	}
+}
+
+// WithSeed mixes seed into the hash so the bit indexes of a key cannot be
+// predicted without it. A seed of 0 is the bloomfilter.js compatible default.
+// Seeded filters can only be combined with filters using the same seed.
+// The seed is kept by the aligned format of ToAlignedBytes, MarshalBinary and
+// WriteWithMeta, and by GoSource, but not by ToBytes, SparseBytes or the
+// SaveAll archive, which refuses seeded filters. NewChecked rejects seeds
+// that make both base hashes equal. The hash is not a
+// cryptographic MAC, so the seed only raises the cost of targeted collisions.
+func WithSeed(seed uint64) Option {
+	return func(bf *BloomFilter) {
+		bf.hashing.seed = seed
+	}
+}
+
+// Hasher computes a 64-bit hash of a value. The high and low 32 bits are used
+// as the two base hashes from which bit indexes are derived.
+// Compatible compares hashers with ==. A func, map or slice hasher is only
+// compatible with itself, and any other hasher that cannot be compared with ==,
+// such as a struct with a slice field, is never compatible, even with itself.
+type Hasher interface {
+	Hash(v []byte) uint64
+}
+
+// WithHasher replaces the default fnv hash with h. Filters using a custom
+// hasher are not compatible with bloomfilter.js, and WithSeed has no effect.
+func WithHasher(h Hasher) Option {
+	return func(bf *BloomFilter) {
+		bf.hashing.hasher = h
+	}
+}
+
+// sameHasher reports whether a and b are the same hasher, without panicking
+// if their dynamic type is not comparable
+func sameHasher(a, b Hasher) (same bool) {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var ta = reflect.TypeOf(a)
+	if ta != reflect.TypeOf(b) {
+		return false
+	}
+	switch ta.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+	}
+	// a comparable struct may still hold a non-comparable value in an interface field
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
+
+// WithEnhancedHashing derives bit indexes with enhanced double hashing, like
+// NewEnhanced, adding an i*(i-1)/2 term to the plain a + i*b recurrence so keys
+// whose second hash is small relative to m do not collapse onto a few bits.
+// The gain is largest when k is large relative to m: averaged over 50 seeds,
+// 100 items in m=1024, k=7 gave 0.79% false positives instead of 0.86%
+// (0.73% in theory), and 20 items in m=320, k=10 gave 0.16% instead of 0.46%.
+// For typical sizes, such as m=9600, k=7 for 1000 items, it makes no difference.
+func WithEnhancedHashing() Option {
+	return func(bf *BloomFilter) {
+		bf.hashing.enhanced = true
+	}
+}
+
+// WithExactOverrides keeps an exact set of the keys marked with MarkExact,
+// for the few keys that must never be a false positive. Test, TestBatch and
+// Freeze answer for them from the set instead of the bloom filter, and Clone
+// copies it. The set is not serialized, so a filter read back from any
+// serialized form, or a MmapFilter, answers for marked keys from the bits.
+func WithExactOverrides() Option {
+	return func(bf *BloomFilter) {
+		bf.overrides = make(map[string]bool)
+	}
+}
+
+// MarkExact marks key for exact membership on a filter created with
+// WithExactOverrides, so Test reports it present exactly when it has been
+// added since it was marked, and returns key so it can be passed to Add.
+// Keys should be marked before they are added: a key that already tests
+// positive when marked stays positive, since the bloom filter cannot tell an
+// earlier add from a false positive.
+// It panics if the filter was not created with WithExactOverrides.
+func (bf *BloomFilter) MarkExact(key []byte) []byte {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	if bf.overrides == nil {
+		panic("bloomfilter: MarkExact requires WithExactOverrides")
+	}
+	if _, ok := bf.overrides[string(key)]; !ok {
+		bf.overrides[string(key)] = bf.test(key)
+	}
+	return key
+}
+
+// copyOverrides returns a copy of a MarkExact set, or nil if there is none
+func copyOverrides(overrides map[string]bool) map[string]bool {
+	if overrides == nil {
+		return nil
+	}
+	var c = make(map[string]bool, len(overrides))
+	for key, present := range overrides {
+		c[key] = present
+	}
+	return c
+}
+
+// WithSummaryBit sets one extra bit per key in the same 32-bit word as its first
+// bit index, and Test checks both before any other index. For filters too large
+// to stay in memory, most absent keys are then rejected after touching a single
+// page instead of up to k. Each key sets k+1 bits, so the filter fills faster and,
+// although Test also checks the extra bit, the false positive rate is slightly
+// higher than with k hash functions alone: at the fill for which k is optimal
+// it rises by a factor of (1-2^(-(k+1)/k))^(k+1) / 2^-k, about 1.03 for k=7.
+// Averaged over 50 seeds, 950 items in m=9600, k=7 gave 0.819% false positives
+// instead of 0.800%. Filters using summary bits are only compatible with each
+// other, and the mode is not included in ToBytes.
+func WithSummaryBit() Option {
+	return func(bf *BloomFilter) {
+		bf.hashing.summary = true
+	}
+}
+
+// WithUnsafeLocking removes locking from the bloom filter. This avoids the cost
+// of the lock when a filter is only used from a single goroutine, or is no
+// longer written, but makes concurrent writes unsafe.
+func WithUnsafeLocking() Option {
+	return func(bf *BloomFilter) {
+		bf.lock = noLock{}
+	}
+}
+
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+// WithByteOrder sets the byte order of the buckets in ToBytes and WriteTo,
+// and of the input to NewFromBytesWithOptions. The default is big-endian, which
+// bloomfilter.js expects. Other formats in this package are always big-endian.
+func WithByteOrder(order binary.ByteOrder) Option {
+	return func(bf *BloomFilter) {
+		bf.order = order
+	}
+}
+
+// NewFromBytesWithOptions creates a new bloom filter from a byte slice like
+// NewFromBytes and applies opts to it. The byte order given by WithByteOrder
+// is used to decode bb.
+func NewFromBytesWithOptions(bb []byte, k int, opts ...Option) *BloomFilter {
+	var bf = NewWithOptions(0, k, opts...)
+	var order = bf.byteOrder()
+	bf.buckets = make([]uint32, len(bb)/4)
+	for i := range bf.buckets {
+		bf.buckets[i] = order.Uint32(bb[i*4:])
+	}
+	bf.m = uint32(len(bf.buckets) * 32)
+	return bf
+}
+
+// NewFromBuckets creates a new bloom filter that uses buckets, as returned by
+// Buckets, without copying it, and applies opts to it. m is 32 times the
+// number of buckets and k specifies the number of hashing functions.
+func NewFromBuckets(buckets []uint32, k int, opts ...Option) *BloomFilter {
+	var bf = NewWithOptions(0, k, opts...)
+	bf.buckets = buckets
+	bf.m = uint32(len(buckets) * 32)
+	return bf
+}
+
+// WithExactCount counts the adds that set at least one new bit, which Count then
+// returns instead of the ApproximateCount estimate. Duplicate adds are never
+// counted, but a new item whose bits were all already set is missed, so the
+// count is a lower bound that drifts further below the truth as the filter fills.
+func WithExactCount() Option {
+	return func(bf *BloomFilter) {
+		bf.exactCount = true
+	}
+}
@@ -0,0 +1,459 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestWithKeySink(t *testing.T) {
+	seen := map[string]int{}
+	f := NewWithOptions(1000, 4, WithKeySink(func(v []byte) {
+		seen[string(v)]++
+	}))
+	key := []byte("abc")
+	f.Add(key)
+	key[0] = 'x'
+	f.AddInt(1)
+	f.AddBatch([][]byte{[]byte("def"), []byte("ghi")})
+	f.AddBatchReportNew([][]byte{[]byte("jkl")})
+	f.AddPacked([]byte("mnop"), []int{0, 2, 4})
+	expected := []string{"abc", "\x00\x00\x00\x01", "def", "ghi", "jkl", "mn", "op"}
+	if len(seen) != len(expected) {
+		t.Log(seen)
+		t.Fail()
+	}
+	for _, v := range expected {
+		if seen[v] != 1 {
+			t.Log(v, seen[v])
+			t.Fail()
+		}
+	}
+}
+
+func TestWithSeed(t *testing.T) {
+	f0 := NewWithOptions(1000, 4, WithSeed(0))
+	f1 := NewWithOptions(1000, 4, WithSeed(1))
+	if !reflect.DeepEqual(f0.locations([]byte("abc")), New(1000, 4).locations([]byte("abc"))) {
+		t.Fail()
+	}
+	if reflect.DeepEqual(f0.locations([]byte("abc")), f1.locations([]byte("abc"))) {
+		t.Fail()
+	}
+	if f0.Compatible(f1) {
+		t.Fail()
+	}
+	f1.Add([]byte("abc"))
+	if !f1.Test([]byte("abc")) || !f1.Freeze().Test([]byte("abc")) {
+		t.Fail()
+	}
+}
+
+type xorHasher uint64
+
+func (h xorHasher) Hash(v []byte) uint64 {
+	x := uint64(h)
+	for _, b := range v {
+		x = (x ^ uint64(b)) * 1099511628211
+	}
+	return x
+}
+
+func TestNewWithOptionsDefaults(t *testing.T) {
+	f1 := New(1000, 4)
+	f2 := NewWithOptions(1000, 4)
+	f1.Add([]byte("abc"))
+	f2.Add([]byte("abc"))
+	if f1.m != f2.m || f1.k != f2.k || !f1.Compatible(f2) {
+		t.Fail()
+	}
+	if !bytes.Equal(f1.ToBytes(), f2.ToBytes()) {
+		t.Fail()
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	f := NewWithOptions(1000, 4,
+		WithHasher(xorHasher(14695981039346656037)),
+		WithUnsafeLocking(),
+		WithByteOrder(binary.LittleEndian))
+	f.Add([]byte("abc"))
+	if !f.Test([]byte("abc")) || f.Test([]byte("def")) {
+		t.Fail()
+	}
+	if f.Compatible(New(1000, 4)) {
+		t.Fail()
+	}
+	x := xorHasher(14695981039346656037).Hash([]byte("abc"))
+	if !reflect.DeepEqual(f.locations([]byte("abc")), hashLocations(uint32(x>>32), uint32(x), f.m, f.k)) {
+		t.Fail()
+	}
+	bb := f.ToBytes()
+	if !bytes.Equal(bb, bucketsToBytes(f.buckets, binary.LittleEndian)) {
+		t.Fail()
+	}
+	f2 := NewFromBytesWithOptions(bb, 4, WithHasher(xorHasher(14695981039346656037)), WithByteOrder(binary.LittleEndian))
+	if !f2.Test([]byte("abc")) || !f.Compatible(f2) || !reflect.DeepEqual(f.buckets, f2.buckets) {
+		t.Fail()
+	}
+	if NewFromBytes(bb, 4).Compatible(f2) {
+		t.Fail()
+	}
+}
+
+func TestWithExactCount(t *testing.T) {
+	m, k := EstimateParameters(1000, 1e-4)
+	exact := NewWithOptions(m, k, WithExactCount())
+	approximate := New(m, k)
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 500; i++ {
+			exact.AddInt(i)
+			approximate.AddInt(i)
+		}
+	}
+	if n := exact.Count(); n != 500 {
+		t.Log(n)
+		t.Fail()
+	}
+	if n := approximate.Count(); n < 450 || n > 550 || n != approximate.ApproximateCount() {
+		t.Log(n)
+		t.Fail()
+	}
+	exact.AddBatch([][]byte{[]byte("abc"), []byte("abc")})
+	exact.AddBatchReportNew([][]byte{[]byte("def"), []byte("def")})
+	if n := exact.Count(); n != 502 {
+		t.Log(n)
+		t.Fail()
+	}
+	exact.Reset()
+	if exact.Count() != 0 {
+		t.Fail()
+	}
+}
+
+type halvesHasher struct{}
+
+func (halvesHasher) Hash(v []byte) uint64 {
+	x := uint64(xorHasher(0).Hash(v)) & 0xffffffff
+	return x<<32 | x
+}
+
+func TestNewChecked(t *testing.T) {
+	if _, err := NewChecked(1000, 4, WithSeed(1)); err != nil {
+		t.Fail()
+	}
+	if _, err := NewChecked(1000, 4, WithHasher(xorHasher(0))); err != nil {
+		t.Fail()
+	}
+	// Both halves of the seed select the same fnv seed.
+	if _, err := NewChecked(1000, 4, WithSeed(1576284489)); err != ErrDegenerateHash {
+		t.Log(err)
+		t.Fail()
+	}
+	if _, err := NewChecked(1000, 4, WithHasher(halvesHasher{})); err != ErrDegenerateHash {
+		t.Log(err)
+		t.Fail()
+	}
+	if _, err := NewChecked(0, 4); err == nil {
+		t.Fail()
+	}
+	if _, err := NewChecked(1000, 0); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSummaryBit(t *testing.T) {
+	m, k := EstimateParameters(10000, 0.01)
+	f := NewWithOptions(m, k, WithSummaryBit())
+	plain := New(m, k)
+	for i := 0; i < 10000; i++ {
+		f.AddInt(i)
+		plain.AddInt(i)
+	}
+	for i := 0; i < 10000; i++ {
+		if !f.TestInt(i) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+	loc := f.Hashes([]byte("abc"))
+	if len(loc) != k+1 || loc[0]/32 != loc[1]/32 || loc[0] == loc[1] {
+		t.Log(loc)
+		t.Fail()
+	}
+	if !reflect.DeepEqual(append(loc[:1:1], loc[2:]...), plain.Hashes([]byte("abc"))) {
+		t.Log("summary bit changed the other indexes")
+		t.Fail()
+	}
+	var fp, plainFP int
+	for i := 10000; i < 110000; i++ {
+		if f.TestInt(i) {
+			fp++
+		}
+		if plain.TestInt(i) {
+			plainFP++
+		}
+	}
+	t.Log(fp, plainFP)
+	if float64(fp) > 1.5*float64(plainFP) {
+		t.Fail()
+	}
+	n := f.ApproximateCount()
+	if n < 9500 || n > 10500 {
+		t.Log(n)
+		t.Fail()
+	}
+	g, err := NewFromMmap(f.ToAlignedBytes())
+	if err != nil || !g.TestInt(1) || g.TestInt(-1) != f.TestInt(-1) {
+		t.Fail()
+	}
+	if f.Compatible(plain) {
+		t.Fail()
+	}
+}
+
+// pagesTouched returns the number of distinct 4KiB pages Test reads for v
+func pagesTouched(f *BloomFilter, v []byte) int {
+	pages := map[uint32]bool{}
+	for _, l := range f.Hashes(v) {
+		pages[l/(4096*8)] = true
+		if f.buckets[l/32]&(1<<(l%32)) == 0 {
+			break
+		}
+	}
+	return len(pages)
+}
+
+func benchmarkNegativePages(b *testing.B, opts ...Option) {
+	m, k := EstimateParameters(1000000, 0.01)
+	f := NewWithOptions(m, k, opts...)
+	for i := 0; i < 1000000; i++ {
+		f.AddInt(i)
+	}
+	b.ResetTimer()
+	pages := 0
+	for i := 0; i < b.N; i++ {
+		v := []byte{byte(i), byte(i >> 8), byte(i >> 16), byte(i >> 24), 0xff}
+		pages += pagesTouched(f, v)
+		f.Test(v)
+	}
+	b.ReportMetric(float64(pages)/float64(b.N), "pages/op")
+}
+
+func BenchmarkNegativePages(b *testing.B) {
+	benchmarkNegativePages(b)
+}
+
+func BenchmarkNegativePagesSummaryBit(b *testing.B) {
+	benchmarkNegativePages(b, WithSummaryBit())
+}
+
+func TestKeySinkBufferReuse(t *testing.T) {
+	var seen [][]byte
+	f := NewWithOptions(1000, 4, WithKeySink(func(v []byte) {
+		seen = append(seen, v)
+	}))
+	buf := []byte("abc")
+	f.Add(buf)
+	copy(buf, "def")
+	f.AddRetained(buf)
+	copy(buf, "xyz")
+	if len(seen) != 2 || string(seen[0]) != "abc" || string(seen[1]) != "def" {
+		t.Log(seen)
+		t.Fail()
+	}
+	if !f.Test([]byte("abc")) || !f.Test([]byte("def")) || f.Test(buf) {
+		t.Fail()
+	}
+}
+
+func TestWithEnhancedHashing(t *testing.T) {
+	if !NewWithOptions(1000, 4, WithEnhancedHashing()).Compatible(NewEnhanced(1000, 4)) {
+		t.Fail()
+	}
+	for _, c := range []struct{ m, k, n int }{{1024, 7, 100}, {320, 10, 20}} {
+		var plain, enhanced int
+		for seed := uint64(0); seed < 20; seed++ {
+			p := NewWithOptions(c.m, c.k, WithSeed(seed))
+			e := NewWithOptions(c.m, c.k, WithSeed(seed), WithEnhancedHashing())
+			for i := 0; i < c.n; i++ {
+				p.AddInt(i)
+				e.AddInt(i)
+			}
+			for i := c.n; i < c.n+10000; i++ {
+				if p.TestInt(i) {
+					plain++
+				}
+				if e.TestInt(i) {
+					enhanced++
+				}
+			}
+		}
+		t.Log(c, plain, enhanced)
+		if enhanced >= plain {
+			t.Fail()
+		}
+	}
+}
+
+func TestWithExactOverrides(t *testing.T) {
+	f := NewWithOptions(64, 1, WithExactOverrides())
+	for i := 0; i < 100; i++ {
+		f.MarkExact([]byte{'x', byte(i)})
+	}
+	f.Add(f.MarkExact([]byte("always")))
+	// fill the filter so every other key is a false positive
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	if f.PopCount() != 64 || !f.Test([]byte("unmarked")) {
+		t.Fatal(f.PopCount())
+	}
+	for i := 0; i < 100; i++ {
+		if f.Test([]byte{'x', byte(i)}) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+	if !f.Test([]byte("always")) {
+		t.Fail()
+	}
+	f.Add([]byte{'x', 1})
+	if !f.Test([]byte{'x', 1}) || f.Test([]byte{'x', 2}) {
+		t.Fail()
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fail()
+		}
+	}()
+	New(64, 1).MarkExact([]byte("x"))
+}
+
+func TestCloneExactOverrides(t *testing.T) {
+	f := NewWithOptions(64, 1, WithExactOverrides())
+	f.MarkExact([]byte("a"))
+	c := f.Clone()
+	c.Add([]byte("a"))
+	if f.Test([]byte("a")) || !c.Test([]byte("a")) {
+		t.Fail()
+	}
+}
+
+func TestExactOverridesAddPaths(t *testing.T) {
+	adds := map[string]func(f *BloomFilter, v []byte){
+		"Add":               func(f *BloomFilter, v []byte) { f.Add(v) },
+		"AddRetained":       func(f *BloomFilter, v []byte) { f.AddRetained(v) },
+		"AddBatch":          func(f *BloomFilter, v []byte) { f.AddBatch([][]byte{v}) },
+		"AddBatchReportNew": func(f *BloomFilter, v []byte) { f.AddBatchReportNew([][]byte{v}) },
+		"AddUnique":         func(f *BloomFilter, v []byte) { f.AddUnique([][]byte{v, v}) },
+	}
+	for name, add := range adds {
+		f := NewWithOptions(64, 1, WithExactOverrides())
+		add(f, f.MarkExact([]byte("a")))
+		if !f.Test([]byte("a")) || !f.TestBatch([][]byte{[]byte("a")})[0] {
+			t.Log(name)
+			t.Fail()
+		}
+	}
+}
+
+func TestExactOverridesReset(t *testing.T) {
+	for _, reset := range []func(f *BloomFilter){
+		func(f *BloomFilter) { f.Reset() },
+		func(f *BloomFilter) { f.ResetWithSeed(1) },
+	} {
+		f := NewWithOptions(64, 1, WithExactOverrides())
+		f.Add(f.MarkExact([]byte("a")))
+		reset(f)
+		if f.Test([]byte("a")) {
+			t.Fail()
+		}
+		f.Add([]byte("a"))
+		if !f.Test([]byte("a")) {
+			t.Fail()
+		}
+	}
+}
+
+func TestExactOverridesAgree(t *testing.T) {
+	f := NewWithOptions(64, 1, WithExactOverrides())
+	var items [][]byte
+	for i := 0; i < 100; i++ {
+		items = append(items, f.MarkExact([]byte{'x', byte(i)}))
+	}
+	for i := 0; i < 100; i++ {
+		f.AddInt(i)
+	}
+	f.Add(items[1])
+	ff := f.Freeze()
+	for i, ok := range f.TestBatch(items) {
+		if ok != f.Test(items[i]) || ok != ff.Test(items[i]) || ok != (i == 1) {
+			t.Log(i)
+			t.Fail()
+		}
+	}
+}
+
+// funcHasher is a hasher that cannot be compared with ==
+type funcHasher func(v []byte) uint64
+
+func (h funcHasher) Hash(v []byte) uint64 {
+	return h(v)
+}
+
+// sliceHasher is a struct hasher that cannot be compared with ==
+type sliceHasher struct {
+	salt []byte
+}
+
+func (h sliceHasher) Hash(v []byte) uint64 {
+	return uint64(len(h.salt)) ^ uint64(fnv_1a(v, 0))<<32 | uint64(fnv_1a(v, 1))
+}
+
+func TestCompatibleNonComparableHasher(t *testing.T) {
+	fn := funcHasher(func(v []byte) uint64 { return uint64(fnv_1a(v, 0))<<32 | uint64(fnv_1a(v, 1)) })
+	other := funcHasher(func(v []byte) uint64 { return 0 })
+	sh := sliceHasher{[]byte("x")}
+	f := NewWithOptions(1000, 4, WithHasher(fn))
+	if !f.Compatible(NewWithOptions(1000, 4, WithHasher(fn))) {
+		t.Fail()
+	}
+	for _, g := range []*BloomFilter{
+		NewWithOptions(1000, 4, WithHasher(other)),
+		NewWithOptions(1000, 4, WithHasher(sh)),
+		New(1000, 4),
+	} {
+		if f.Compatible(g) {
+			t.Fail()
+		}
+		if _, err := f.SnapshotUnion(g); err != ErrHashMismatch {
+			t.Fail()
+		}
+		if err := f.OrInto(g); err != ErrHashMismatch {
+			t.Fail()
+		}
+		if _, _, err := f.Diff(g); err != ErrHashMismatch {
+			t.Fail()
+		}
+	}
+	s := NewWithOptions(1000, 4, WithHasher(sh))
+	if s.Compatible(s) || s.Compatible(NewWithOptions(1000, 4, WithHasher(sh))) {
+		t.Fail()
+	}
+}
+
+func TestExactOverridesAddThenMark(t *testing.T) {
+	f := NewWithOptions(1000, 4, WithExactOverrides())
+	f.Add([]byte("a"))
+	f.MarkExact([]byte("a"))
+	f.MarkExact([]byte("b"))
+	if !f.Test([]byte("a")) || f.Test([]byte("b")) {
+		t.Fail()
+	}
+	f.Reset()
+	if f.Test([]byte("a")) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,32 @@
+package bloomfilter
+
+// ReadThrough fronts an expensive backing store lookup with a bloom filter that
+// holds every key in the store, so lookups of keys that are definitely absent
+// never reach the store.
+type ReadThrough struct {
+	bf *BloomFilter
+}
+
+// NewReadThrough creates a read-through wrapper around bf, which must already
+// contain every key present in the backing store.
+func NewReadThrough(bf *BloomFilter) *ReadThrough {
+	return &ReadThrough{bf: bf}
+}
+
+// Add records that key has been written to the backing store
+func (rt *ReadThrough) Add(key []byte) {
+	rt.bf.Add(key)
+}
+
+// Get returns the value for key from load, unless the bloom filter shows that
+// key is definitely absent, in which case load is not called.
+func (rt *ReadThrough) Get(key []byte, load func([]byte) ([]byte, bool)) ([]byte, bool) {
+	if !rt.bf.Test(key) {
+		return nil, false
+	}
+	v, ok := load(key)
+	if ok {
+		rt.bf.Add(key)
+	}
+	return v, ok
+}
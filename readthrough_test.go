@@ -0,0 +1,43 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReadThrough(t *testing.T) {
+	store := map[string][]byte{}
+	m, k := EstimateParameters(100, 1e-3)
+	rt := NewReadThrough(New(m, k))
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		store[key] = []byte(key)
+		rt.Add([]byte(key))
+	}
+	loads := 0
+	load := func(key []byte) ([]byte, bool) {
+		loads++
+		v, ok := store[string(key)]
+		return v, ok
+	}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		v, ok := rt.Get([]byte(key), load)
+		if !ok || string(v) != key {
+			t.Fatal(key)
+		}
+	}
+	if loads != 100 {
+		t.Fail()
+	}
+	loads = 0
+	for i := 0; i < 1000; i++ {
+		if _, ok := rt.Get([]byte(fmt.Sprintf("absent-%d", i)), load); ok {
+			t.Fatal(i)
+		}
+	}
+	if loads > 10 {
+		t.Log(loads)
+		t.Fail()
+	}
+}
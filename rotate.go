@@ -0,0 +1,75 @@
+package bloomfilter
+
+import (
+	"io"
+	"math"
+	"sync"
+)
+
+// RotatingBloomFilter accepts writes into a current bloom filter that Rotate
+// swaps for a fresh empty one, so a filter can be serialized and shipped
+// while new writes continue into its replacement.
+type RotatingBloomFilter struct {
+	m       int
+	k       int
+	opts    []Option
+	current *BloomFilter
+	lock    sync.RWMutex
+}
+
+// NewRotating creates a rotating bloom filter whose filters are created with
+// NewWithOptions(m, k, opts...).
+func NewRotating(m, k int, opts ...Option) *RotatingBloomFilter {
+	return &RotatingBloomFilter{
+		m:       m,
+		k:       k,
+		opts:    opts,
+		current: NewWithOptions(m, k, opts...),
+	}
+}
+
+// RotationParams returns m and k for each filter of a rotating bloom filter
+// holding perWindowN items per window, such that a query testing two
+// consecutive windows, the current filter and the one last returned by
+// Rotate, has a false positive rate of about p.
+func RotationParams(perWindowN int, p float64) (m, k int) {
+	return EstimateParameters(perWindowN, 1-math.Sqrt(1-p))
+}
+
+// NewRotatingWindow creates a rotating bloom filter sized with RotationParams.
+func NewRotatingWindow(perWindowN int, p float64, opts ...Option) *RotatingBloomFilter {
+	var m, k = RotationParams(perWindowN, p)
+	return NewRotating(m, k, opts...)
+}
+
+// Add adds a byte array to the current bloom filter
+func (rf *RotatingBloomFilter) Add(v []byte) {
+	rf.lock.RLock()
+	defer rf.lock.RUnlock()
+	rf.current.Add(v)
+}
+
+// Test evaluates a byte array to determine whether it is (probably) in the current bloom filter
+func (rf *RotatingBloomFilter) Test(v []byte) bool {
+	rf.lock.RLock()
+	defer rf.lock.RUnlock()
+	return rf.current.Test(v)
+}
+
+// Rotate swaps in a new empty bloom filter and returns the previous one. Every
+// Add that returned before Rotate was called is in the returned filter, and no
+// Add writes to it once Rotate returns, so it can be serialized safely.
+func (rf *RotatingBloomFilter) Rotate() *BloomFilter {
+	var next = NewWithOptions(rf.m, rf.k, rf.opts...)
+	rf.lock.Lock()
+	defer rf.lock.Unlock()
+	var old = rf.current
+	rf.current = next
+	return old
+}
+
+// RotateTo rotates the filter and writes the previous one to w with WriteTo
+// while writes continue into the new one.
+func (rf *RotatingBloomFilter) RotateTo(w io.Writer) (int64, error) {
+	return rf.Rotate().WriteTo(w)
+}
@@ -0,0 +1,77 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestRotate(t *testing.T) {
+	rf := NewRotating(1000, 4)
+	rf.Add([]byte("abc"))
+	var buf bytes.Buffer
+	if _, err := rf.RotateTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if rf.Test([]byte("abc")) || !NewFromBytes(buf.Bytes(), 4).Test([]byte("abc")) {
+		t.Fail()
+	}
+}
+
+func TestRotateDuringAdds(t *testing.T) {
+	rf := NewRotating(10000, 4)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			rf.Add([]byte{byte(i), byte(i >> 8)})
+		}
+	}()
+	var filters []*BloomFilter
+	for i := 0; i < 20; i++ {
+		filters = append(filters, rf.Rotate())
+		runtime.Gosched()
+	}
+	wg.Wait()
+	filters = append(filters, rf.Rotate())
+	for i := 0; i < 2000; i++ {
+		if !TestQuorum([]byte{byte(i), byte(i >> 8)}, 1, filters...) {
+			t.Fatal(i)
+		}
+	}
+}
+
+func TestRotationParams(t *testing.T) {
+	const n, p = 10000, 0.01
+	m, k := RotationParams(n, p)
+	if wm, _ := EstimateParameters(n, p); m <= wm {
+		t.Log(m, wm)
+		t.Fail()
+	}
+	rf := NewRotatingWindow(n, p)
+	for i := 0; i < n; i++ {
+		rf.Add([]byte(fmt.Sprint("a", i)))
+	}
+	previous := rf.Rotate()
+	for i := 0; i < n; i++ {
+		rf.Add([]byte(fmt.Sprint("b", i)))
+	}
+	if previous.M() != m || previous.K() != k {
+		t.Fail()
+	}
+	fp := 0
+	for i := 0; i < 100000; i++ {
+		v := []byte(fmt.Sprint("c", i))
+		if rf.Test(v) || previous.Test(v) {
+			fp++
+		}
+	}
+	t.Log(fp)
+	if rate := float64(fp) / 100000; rate > 1.2*p {
+		t.Log(rate)
+		t.Fail()
+	}
+}
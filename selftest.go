@@ -0,0 +1,54 @@
+package bloomfilter
+
+import (
+	"fmt"
+)
+
+type selfTestVector struct {
+	v        string
+	m        uint32
+	enhanced bool
+	want     []uint32
+}
+
+// selfTestVectors are the bit indexes of fixed inputs under the default
+// hashing, with and without enhanced double hashing, for a power-of-two m
+// and for an m that is not.
+var selfTestVectors = []selfTestVector{
+	{"", 1000, false, []uint32{14, 765, 516, 267}},
+	{"a", 1000, false, []uint32{703, 677, 651, 625}},
+	{"foobar", 1000, false, []uint32{761, 354, 947, 540}},
+	{"bloomfilter", 1000, false, []uint32{432, 733, 34, 335}},
+	{"", 1000, true, []uint32{14, 765, 517, 270}},
+	{"a", 1000, true, []uint32{703, 677, 652, 628}},
+	{"foobar", 1000, true, []uint32{761, 354, 948, 543}},
+	{"bloomfilter", 1000, true, []uint32{432, 733, 35, 338}},
+	{"", 1024, false, []uint32{926, 437, 972, 483}},
+	{"a", 1024, false, []uint32{207, 637, 43, 473}},
+	{"foobar", 1024, false, []uint32{641, 698, 755, 812}},
+	{"bloomfilter", 1024, false, []uint32{192, 381, 570, 759}},
+	{"", 1024, true, []uint32{926, 437, 973, 486}},
+	{"a", 1024, true, []uint32{207, 637, 44, 476}},
+	{"foobar", 1024, true, []uint32{641, 698, 756, 815}},
+	{"bloomfilter", 1024, true, []uint32{192, 381, 571, 762}},
+}
+
+// SelfTest hashes a fixed set of inputs and compares their bit indexes with
+// known good values, returning an error on any mismatch. It guards against a
+// miscompiled or accidentally altered hash and is cheap enough to run on startup.
+func SelfTest() error {
+	return selfTest(selfTestVectors)
+}
+
+func selfTest(vectors []selfTestVector) error {
+	for _, tv := range vectors {
+		var got = hashing{enhanced: tv.enhanced}.locations([]byte(tv.v), tv.m, len(tv.want))
+		for i := range got {
+			if got[i] != tv.want[i] {
+				return fmt.Errorf("bloomfilter: self-test failed for %q with m=%d, enhanced=%t: got %v, want %v",
+					tv.v, tv.m, tv.enhanced, got, tv.want)
+			}
+		}
+	}
+	return nil
+}
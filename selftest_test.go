@@ -0,0 +1,23 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+	for i := range selfTestVectors {
+		var vectors = make([]selfTestVector, len(selfTestVectors))
+		copy(vectors, selfTestVectors)
+		var want = append([]uint32(nil), vectors[i].want...)
+		want[len(want)-1]++
+		vectors[i].want = want
+		if selfTest(vectors) == nil {
+			t.Log("perturbed vector", i, "passed")
+			t.Fail()
+		}
+	}
+}
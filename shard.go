@@ -0,0 +1,13 @@
+package bloomfilter
+
+// OwnsHash reports whether the primary hash of v falls in the range of shard
+// shardIndex when the 32-bit hash space is split into shardCount equal ranges.
+// It uses the filter's hashing scheme, so filters sharing a scheme agree on
+// which shard owns each key. It returns false if shardIndex is out of range.
+func (bf *BloomFilter) OwnsHash(v []byte, shardCount, shardIndex int) bool {
+	if shardCount < 1 || shardIndex < 0 || shardIndex >= shardCount {
+		return false
+	}
+	var a, _ = bf.hashing.baseHashes(v)
+	return int(uint64(a)*uint64(shardCount)>>32) == shardIndex
+}
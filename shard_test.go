@@ -0,0 +1,34 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestOwnsHash(t *testing.T) {
+	f := New(1000, 4)
+	shards := 8
+	counts := make([]int, shards)
+	n := 80000
+	for i := 0; i < n; i++ {
+		v := []byte{byte(i), byte(i >> 8), byte(i >> 16)}
+		owners := 0
+		for s := 0; s < shards; s++ {
+			if f.OwnsHash(v, shards, s) {
+				counts[s]++
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Fatal(i, owners)
+		}
+	}
+	for s, c := range counts {
+		if c < n/shards*9/10 || c > n/shards*11/10 {
+			t.Log(s, c)
+			t.Fail()
+		}
+	}
+	if f.OwnsHash([]byte("abc"), 0, 0) || f.OwnsHash([]byte("abc"), 4, 4) || f.OwnsHash([]byte("abc"), 4, -1) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,72 @@
+package bloomfilter
+
+import (
+	"math"
+)
+
+// sketchFold is the largest number of bits set positions are folded into by MinHashSketch
+const sketchFold = 1 << 16
+
+// MinHashSketch returns a minhash signature of size values over the set bits of
+// the bloom filter, folded modulo the largest power of two that divides m, up
+// to 65536. Because (a + i*b) mod m mod 2^t = (a + i*b) mod 2^t whenever 2^t
+// divides m, sketches of filters with different m but the same k and hashing
+// describe the same folded bit positions, and SketchSimilarity estimates the
+// Jaccard similarity of their folded bit sets. This tracks the Jaccard
+// similarity of the added elements while the folded sets are sparse, but
+// overstates it as they fill, so it is most accurate for m that are multiples
+// of 65536 and for filters holding far fewer than 65536/k elements. Sketches
+// are only comparable between filters that folded to the same number of bits.
+func (bf *BloomFilter) MinHashSketch(size int) []uint32 {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var fold = bf.m & -bf.m
+	if fold > sketchFold || fold == 0 {
+		fold = sketchFold
+	}
+	var seen = make([]bool, fold)
+	for i, bucket := range bf.buckets {
+		for j := uint32(0); j < 32; j++ {
+			if bucket&(1<<j) != 0 {
+				seen[(uint32(i)*32+j)%fold] = true
+			}
+		}
+	}
+	var r = make([]uint32, size)
+	for j := range r {
+		r[j] = math.MaxUint32
+	}
+	for x, ok := range seen {
+		if !ok {
+			continue
+		}
+		for j := range r {
+			if h := sketchHash(uint32(x), uint32(j)); h < r[j] {
+				r[j] = h
+			}
+		}
+	}
+	return r
+}
+
+// sketchHash is the j-th hash function of MinHashSketch
+func sketchHash(x, j uint32) uint32 {
+	return fnv_mix(fnv_multiply(x^2166136261) ^ fnv_multiply(j+1576284489))
+}
+
+// SketchSimilarity estimates the Jaccard similarity of the sets two sketches
+// from MinHashSketch were computed over, as the fraction of equal values.
+// The standard error is about 1/sqrt(len(a)). It returns 0 if the sketches
+// have different sizes or are empty.
+func SketchSimilarity(a, b []uint32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var n = 0
+	for i := range a {
+		if a[i] == b[i] {
+			n++
+		}
+	}
+	return float64(n) / float64(len(a))
+}
@@ -0,0 +1,35 @@
+package bloomfilter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMinHashSketch(t *testing.T) {
+	a := New(1<<17, 4)
+	b := New(3<<16, 4)
+	for i := 0; i < 1000; i++ {
+		a.AddInt(i)
+	}
+	for i := 500; i < 1500; i++ {
+		b.AddInt(i)
+	}
+	sa, sb := a.MinHashSketch(512), b.MinHashSketch(512)
+	// 500 shared elements out of 1500
+	estimate := SketchSimilarity(sa, sb)
+	t.Log(estimate)
+	if math.Abs(estimate-1.0/3) > 0.1 {
+		t.Fail()
+	}
+	if SketchSimilarity(sa, a.MinHashSketch(512)) != 1 || SketchSimilarity(sa, sb[:1]) != 0 {
+		t.Fail()
+	}
+	c := New(1<<17, 4)
+	for i := 5000; i < 6000; i++ {
+		c.AddInt(i)
+	}
+	if s := SketchSimilarity(sa, c.MinHashSketch(512)); s > 0.1 {
+		t.Log(s)
+		t.Fail()
+	}
+}
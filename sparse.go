@@ -0,0 +1,67 @@
+package bloomfilter
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// SparseBytes returns the set bits of the bloom filter as a uvarint count
+// followed by the uvarint gaps between their ascending indexes, the first gap
+// being the first index. Each gap under 128 takes one byte, so SparseBytes is
+// smaller than ToBytes until about one bit in eight is set, and far smaller
+// for a filter holding a handful of items. m and k are not stored.
+func (bf *BloomFilter) SparseBytes() []byte {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var n = popCount(bf.buckets)
+	// size for gaps of the average length; append grows bb if they are uneven
+	var gapBytes = (bits.Len32(bf.m/uint32(n+1)) + 6) / 7
+	if gapBytes == 0 {
+		gapBytes = 1
+	}
+	var bb = make([]byte, 0, binary.MaxVarintLen32+gapBytes*n)
+	bb = appendUvarint(bb, uint64(n))
+	var last uint32
+	for i, bucket := range bf.buckets {
+		for bucket != 0 {
+			var l = uint32(i)*32 + uint32(bits.TrailingZeros32(bucket))
+			bb = appendUvarint(bb, uint64(l-last))
+			last = l
+			bucket &= bucket - 1
+		}
+	}
+	return bb
+}
+
+func appendUvarint(bb []byte, x uint64) []byte {
+	var a [binary.MaxVarintLen64]byte
+	return append(bb, a[:binary.PutUvarint(a[:], x)]...)
+}
+
+// NewFromSparseBytes creates a new bloom filter of m bits and k hashing functions
+// from the output of SparseBytes. It returns ErrInvalidFormat if bb is malformed
+// or has an index that does not fit in m bits.
+func NewFromSparseBytes(bb []byte, m, k int) (*BloomFilter, error) {
+	var bf = New(m, k)
+	var count, n = binary.Uvarint(bb)
+	if n <= 0 || count > uint64(bf.m) {
+		return nil, ErrInvalidFormat
+	}
+	bb = bb[n:]
+	var l uint64
+	for i := uint64(0); i < count; i++ {
+		gap, n := binary.Uvarint(bb)
+		if n <= 0 || (i > 0 && gap == 0) {
+			return nil, ErrInvalidFormat
+		}
+		bb = bb[n:]
+		if l += gap; l >= uint64(bf.m) {
+			return nil, ErrInvalidFormat
+		}
+		bf.buckets[l/32] |= 1 << (l % 32)
+	}
+	if len(bb) != 0 {
+		return nil, ErrInvalidFormat
+	}
+	return bf, nil
+}
@@ -0,0 +1,69 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSparseBytes(t *testing.T) {
+	f := New(100000, 1)
+	for i := 0; i < 5; i++ {
+		f.AddInt(i)
+	}
+	if f.PopCount() != 5 {
+		t.Fatal(f.PopCount())
+	}
+	bb := f.SparseBytes()
+	t.Log(len(bb), len(f.ToBytes()))
+	if len(bb) > 20 {
+		t.Fail()
+	}
+	g, err := NewFromSparseBytes(bb, 100000, 1)
+	if err != nil || !bytes.Equal(g.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+	e, err := NewFromSparseBytes(New(1000, 4).SparseBytes(), 1000, 4)
+	if err != nil || e.PopCount() != 0 {
+		t.Fail()
+	}
+}
+
+func TestSparseBytesDense(t *testing.T) {
+	f := New(1000, 4)
+	f.buckets[0] = 0xffffffff
+	f.buckets[len(f.buckets)-1] = 1 << 31
+	g, err := NewFromSparseBytes(f.SparseBytes(), 1000, 4)
+	if err != nil || !bytes.Equal(g.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+}
+
+func TestSparseBytesInvalid(t *testing.T) {
+	f := New(100000, 4)
+	f.AddInt(1)
+	bb := f.SparseBytes()
+	for _, b := range [][]byte{nil, bb[:len(bb)-1], append(bb, 0), {1, 0xff, 0xff, 0xff, 0x7f}} {
+		if _, err := NewFromSparseBytes(b, 100000, 4); err != ErrInvalidFormat {
+			t.Log(b)
+			t.Fail()
+		}
+	}
+	if _, err := NewFromSparseBytes(bb, 32, 4); err != ErrInvalidFormat {
+		t.Fail()
+	}
+}
+
+func TestSparseBytesCapacity(t *testing.T) {
+	for _, n := range []int{0, 10, 1000, 100000} {
+		f := New(100000, 4)
+		for i := 0; i < n; i++ {
+			f.AddInt(i)
+		}
+		bb := f.SparseBytes()
+		if cap(bb) > 2*len(bb)+binary.MaxVarintLen32 {
+			t.Log(n, len(bb), cap(bb))
+			t.Fail()
+		}
+	}
+}
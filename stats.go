@@ -0,0 +1,42 @@
+package bloomfilter
+
+import (
+	"sync/atomic"
+)
+
+// Stats holds usage counters for a bloom filter.
+type Stats struct {
+	Adds       uint64 // number of Add calls
+	Tests      uint64 // number of Test calls
+	Positives  uint64 // number of Test calls that returned true
+	Duplicates uint64 // number of adds that set no new bit, probably duplicates
+}
+
+// EnableStats turns usage counters on or off. Counters are off by default,
+// in which case Add and Test perform no atomic operations.
+func (bf *BloomFilter) EnableStats(enabled bool) {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	bf.stats = enabled
+}
+
+// Stats returns the current usage counters
+func (bf *BloomFilter) Stats() Stats {
+	return Stats{
+		Adds:       atomic.LoadUint64(&bf.adds),
+		Tests:      atomic.LoadUint64(&bf.tests),
+		Positives:  atomic.LoadUint64(&bf.positives),
+		Duplicates: atomic.LoadUint64(&bf.duplicates),
+	}
+}
+
+// DuplicateRate returns the fraction of adds counted since stats were enabled
+// that set no new bit. It overstates the true duplicate rate by about the
+// false positive rate at the time of each add. It returns 0 if there were no adds.
+func (bf *BloomFilter) DuplicateRate() float64 {
+	var s = bf.Stats()
+	if s.Adds == 0 {
+		return 0
+	}
+	return float64(s.Duplicates) / float64(s.Adds)
+}
@@ -0,0 +1,58 @@
+package bloomfilter
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	f := New(1000, 4)
+	f.EnableStats(true)
+	f.Add([]byte("abc"))
+	f.AddInt(1)
+	f.Test([]byte("abc"))
+	f.Test([]byte("def"))
+	f.TestInt(1)
+	s := f.Stats()
+	if s.Adds != 2 || s.Tests != 3 || s.Positives != 2 {
+		t.Log(s)
+		t.Fail()
+	}
+}
+
+func TestStatsDisabled(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	f.Test([]byte("abc"))
+	f.EnableStats(true)
+	f.EnableStats(false)
+	f.Add([]byte("def"))
+	f.Test([]byte("def"))
+	if s := f.Stats(); s != (Stats{}) {
+		t.Log(s)
+		t.Fail()
+	}
+}
+
+func TestDuplicateRate(t *testing.T) {
+	m, k := EstimateParameters(10000, 1e-4)
+	f := New(m, k)
+	f.EnableStats(true)
+	if f.DuplicateRate() != 0 {
+		t.Fail()
+	}
+	// 3000 distinct values, 1000 of them added twice
+	for i := 0; i < 3000; i++ {
+		f.AddInt(i)
+	}
+	for i := 0; i < 1000; i++ {
+		f.AddInt(i)
+	}
+	if r := f.DuplicateRate(); r < 0.249 || r > 0.26 {
+		t.Log(r)
+		t.Fail()
+	}
+	if s := f.Stats(); s.Adds != 4000 || s.Duplicates < 1000 {
+		t.Log(s)
+		t.Fail()
+	}
+}
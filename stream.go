@@ -0,0 +1,34 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// FilterStream reads newline-delimited candidate keys from r and writes those
+// that (probably) are in the bloom filter to w, each followed by a newline.
+// It returns the number of keys written.
+func (bf *BloomFilter) FilterStream(r io.Reader, w io.Writer) (kept int, err error) {
+	var br = bufio.NewReader(r)
+	var bw = bufio.NewWriter(w)
+	for {
+		line, rerr := br.ReadBytes('\n')
+		if rerr != nil && rerr != io.EOF {
+			return kept, rerr
+		}
+		var key = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) > 0 && bf.Test(key) {
+			if _, err := bw.Write(key); err != nil {
+				return kept, err
+			}
+			if err := bw.WriteByte('\n'); err != nil {
+				return kept, err
+			}
+			kept++
+		}
+		if rerr == io.EOF {
+			return kept, bw.Flush()
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFilterStream(t *testing.T) {
+	f := New(1000, 4)
+	f.Add([]byte("abc"))
+	f.Add([]byte("ghi"))
+	f.Add([]byte(""))
+	var out bytes.Buffer
+	kept, err := f.FilterStream(strings.NewReader("abc\ndef\n\nghi\njkl\nabc"), &out)
+	if err != nil || kept != 4 {
+		t.Log(kept, err)
+		t.Fail()
+	}
+	if out.String() != "abc\n\nghi\nabc\n" {
+		t.Logf("%q", out.String())
+		t.Fail()
+	}
+}
@@ -0,0 +1,136 @@
+package bloomfilter
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// AddStruct adds v, a struct or pointer to a struct, to the bloom filter.
+// Its exported fields are encoded in declaration order, each tagged with its
+// name and kind, so structs of different types or with fields in a different
+// order are different items. Unexported fields are ignored, so a value
+// implementing encoding.BinaryMarshaler, such as time.Time, is encoded with
+// MarshalBinary instead. Maps, channels, funcs and interfaces are not supported.
+// Reflection makes AddStruct much slower than encoding keys by hand.
+func (bf *BloomFilter) AddStruct(v interface{}) error {
+	bb, err := encodeStruct(v)
+	if err != nil {
+		return err
+	}
+	bf.Add(bb)
+	return nil
+}
+
+// TestStruct evaluates v to determine whether it is (probably) in the bloom
+// filter. See AddStruct.
+func (bf *BloomFilter) TestStruct(v interface{}) (bool, error) {
+	bb, err := encodeStruct(v)
+	if err != nil {
+		return false, err
+	}
+	return bf.Test(bb), nil
+}
+
+func encodeStruct(v interface{}) ([]byte, error) {
+	var rv = reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bloomfilter: AddStruct of %T, not a struct", v)
+	}
+	return appendValue(nil, rv, map[pointer]bool{})
+}
+
+// pointer identifies a pointer being followed by appendValue. The type is
+// needed because a struct and its first field share an address.
+type pointer struct {
+	t    reflect.Type
+	addr uintptr
+}
+
+// appendValue appends a kind tag and the encoding of v to bb. path holds the
+// pointers followed to reach v, so cycles are reported instead of followed forever.
+func appendValue(bb []byte, v reflect.Value, path map[pointer]bool) ([]byte, error) {
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.BinaryMarshaler); ok && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+			data, err := m.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			return appendBytes(append(bb, 'm'), data), nil
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(bb, 'b', 1), nil
+		}
+		return append(bb, 'b', 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendUint64(append(bb, 'i'), uint64(v.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint64(append(bb, 'u'), v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		var f = v.Float()
+		if f == 0 {
+			f = 0 // -0 and +0 are the same value
+		}
+		return appendUint64(append(bb, 'f'), math.Float64bits(f)), nil
+	case reflect.String:
+		return appendBytes(append(bb, 's'), []byte(v.String())), nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return appendBytes(append(bb, 'y'), v.Bytes()), nil
+		}
+		bb = appendUint32(append(bb, 'a'), uint32(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			var err error
+			if bb, err = appendValue(bb, v.Index(i), path); err != nil {
+				return nil, err
+			}
+		}
+		return bb, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return append(bb, 'n'), nil
+		}
+		var p = pointer{v.Type(), v.Pointer()}
+		if path[p] {
+			return nil, fmt.Errorf("bloomfilter: AddStruct of cyclic %s", v.Type())
+		}
+		path[p] = true
+		defer delete(path, p)
+		return appendValue(append(bb, 'p'), v.Elem(), path)
+	case reflect.Struct:
+		var t = v.Type()
+		bb = appendBytes(append(bb, 'S'), []byte(t.PkgPath()+"."+t.Name()))
+		for i := 0; i < t.NumField(); i++ {
+			var f = t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			bb = appendBytes(bb, []byte(f.Name))
+			var err error
+			if bb, err = appendValue(bb, v.Field(i), path); err != nil {
+				return nil, fmt.Errorf("%w in field %s", err, f.Name)
+			}
+		}
+		return append(bb, 'E'), nil
+	}
+	return nil, fmt.Errorf("bloomfilter: AddStruct does not support %s", v.Type())
+}
+
+// appendBytes appends the big-endian 32-bit length of v followed by v to bb
+func appendBytes(bb, v []byte) []byte {
+	return append(appendUint32(bb, uint32(len(v))), v...)
+}
+
+func appendUint64(bb []byte, v uint64) []byte {
+	var a [8]byte
+	binary.BigEndian.PutUint64(a[:], v)
+	return append(bb, a[:]...)
+}
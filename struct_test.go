@@ -0,0 +1,111 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type point struct {
+	X, Y   int
+	Label  string
+	hidden int
+}
+
+type swapped struct {
+	Y, X   int
+	Label  string
+	hidden int
+}
+
+type event struct {
+	At    time.Time
+	Tags  []string
+	Raw   []byte
+	Where *point
+	Score float64
+}
+
+func TestAddStruct(t *testing.T) {
+	f := New(10000, 4)
+	if err := f.AddStruct(point{X: 1, Y: 2, Label: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	for v, want := range map[interface{}]bool{
+		point{X: 1, Y: 2, Label: "a"}:            true,
+		point{X: 1, Y: 2, Label: "a", hidden: 3}: true,
+		&point{X: 1, Y: 2, Label: "a"}:           true,
+		point{X: 1, Y: 3, Label: "a"}:            false,
+		swapped{X: 1, Y: 2, Label: "a"}:          false,
+		swapped{Y: 1, X: 2, Label: "a"}:          false,
+	} {
+		if ok, err := f.TestStruct(v); err != nil || ok != want {
+			t.Logf("%#v %v", v, err)
+			t.Fail()
+		}
+	}
+	a, _ := encodeStruct(point{X: 1, Y: 2})
+	b, _ := encodeStruct(swapped{Y: 1, X: 2})
+	if bytes.Equal(a, b) {
+		t.Log("field order ignored")
+		t.Fail()
+	}
+}
+
+func TestAddStructNested(t *testing.T) {
+	f := New(10000, 4)
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := event{At: at, Tags: []string{"x", "y"}, Raw: []byte{1}, Where: &point{X: 1}, Score: -0.0}
+	if err := f.AddStruct(e); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := f.TestStruct(event{At: at, Tags: []string{"x", "y"}, Raw: []byte{1}, Where: &point{X: 1}}); !ok {
+		t.Fail()
+	}
+	for _, v := range []event{
+		{At: at.Add(time.Second), Tags: []string{"x", "y"}, Raw: []byte{1}, Where: &point{X: 1}},
+		{At: at, Tags: []string{"xy"}, Raw: []byte{1}, Where: &point{X: 1}},
+		{At: at, Tags: []string{"x", "y"}, Raw: []byte{1}},
+	} {
+		if ok, _ := f.TestStruct(v); ok {
+			t.Logf("%#v", v)
+			t.Fail()
+		}
+	}
+}
+
+func TestAddStructUnsupported(t *testing.T) {
+	f := New(1000, 4)
+	if err := f.AddStruct(42); err == nil {
+		t.Fail()
+	}
+	if err := f.AddStruct(struct{ M map[string]int }{}); err == nil {
+		t.Fail()
+	}
+	if err := f.AddStruct((*point)(nil)); err == nil {
+		t.Fail()
+	}
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+func TestAddStructCycle(t *testing.T) {
+	f := New(1000, 4)
+	list := &node{Value: 1, Next: &node{Value: 2}}
+	if err := f.AddStruct(list); err != nil {
+		t.Fatal(err)
+	}
+	list.Next.Next = list
+	if err := f.AddStruct(list); err == nil {
+		t.Fail()
+	}
+	// a pointer reached twice without a cycle is not an error
+	shared := &point{X: 1}
+	if err := f.AddStruct(struct{ A, B *point }{shared, shared}); err != nil {
+		t.Log(err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,112 @@
+package bloomfilter
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// WordChecksums returns the CRC-32 of each block of blockSize buckets of the
+// bloom filter, the last block holding any remainder. Comparing the checksums
+// of two copies of a filter finds the blocks that differ, which can then be
+// copied with Block and applied with Patch. Like Block, it returns nil if
+// blockSize is not positive.
+func (bf *BloomFilter) WordChecksums(blockSize int) []uint32 {
+	if blockSize < 1 {
+		return nil
+	}
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var n = (len(bf.buckets) + blockSize - 1) / blockSize
+	var r = make([]uint32, n)
+	var bb = make([]byte, 0, blockSize*4)
+	for i := range r {
+		bb = bb[:0]
+		for _, bucket := range bf.block(blockSize, i) {
+			bb = appendUint32(bb, bucket)
+		}
+		r[i] = crc32.ChecksumIEEE(bb)
+	}
+	return r
+}
+
+// block returns the buckets of block i of blockSize buckets, or nil if it is out of range
+func (bf *BloomFilter) block(blockSize, i int) []uint32 {
+	var start = i * blockSize
+	if blockSize < 1 || i < 0 || start >= len(bf.buckets) {
+		return nil
+	}
+	var end = start + blockSize
+	if end > len(bf.buckets) {
+		end = len(bf.buckets)
+	}
+	return bf.buckets[start:end]
+}
+
+// Block returns a copy of block blockIndex of blockSize buckets, as used by
+// WordChecksums, or nil if blockSize is not positive or the block is out of range.
+func (bf *BloomFilter) Block(blockSize, blockIndex int) []uint32 {
+	bf.lock.RLock()
+	defer bf.lock.RUnlock()
+	var b = bf.block(blockSize, blockIndex)
+	if b == nil {
+		return nil
+	}
+	var r = make([]uint32, len(b))
+	copy(r, b)
+	return r
+}
+
+// Patch replaces block blockIndex of blockSize buckets with words, which must
+// match its length. It returns an error if blockSize is not positive, the block
+// is out of range or words has the wrong length.
+// The count kept by WithExactCount is not updated.
+func (bf *BloomFilter) Patch(blockSize, blockIndex int, words []uint32) error {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	var b = bf.block(blockSize, blockIndex)
+	if b == nil {
+		return errors.New("bloomfilter: block out of range")
+	}
+	if len(words) != len(b) {
+		return fmt.Errorf("bloomfilter: patch of %d words for a block of %d", len(words), len(b))
+	}
+	copy(b, words)
+	return nil
+}
+
+// Diff returns the indexes of the buckets of bf that differ from those of old,
+// which must be Compatible, and their values in bf. ApplyDiff on a copy of old
+// then reproduces bf.
+func (bf *BloomFilter) Diff(old *BloomFilter) (indices []uint32, words []uint32, err error) {
+	if err := bf.checkCompatible(old); err != nil {
+		return nil, nil, err
+	}
+	var a, b = old.snapshot(), bf.snapshot()
+	for i := range b {
+		if a[i] != b[i] {
+			indices = append(indices, uint32(i))
+			words = append(words, b[i])
+		}
+	}
+	return indices, words, nil
+}
+
+// ApplyDiff sets the buckets at indices to words, as returned by Diff.
+// The count kept by WithExactCount is not updated.
+func (bf *BloomFilter) ApplyDiff(indices []uint32, words []uint32) error {
+	if len(indices) != len(words) {
+		return errors.New("bloomfilter: indices and words differ in length")
+	}
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	for _, i := range indices {
+		if int(i) >= len(bf.buckets) {
+			return errors.New("bloomfilter: bucket index out of range")
+		}
+	}
+	for j, i := range indices {
+		bf.buckets[i] = words[j]
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	src := New(10000, 4)
+	for i := 0; i < 100; i++ {
+		src.AddInt(i)
+	}
+	dst := NewFromBytes(src.ToBytes(), 4)
+	for i := 100; i < 110; i++ {
+		src.AddInt(i)
+	}
+	// 313 buckets, so the last block is short
+	const blockSize = 16
+	a, b := src.WordChecksums(blockSize), dst.WordChecksums(blockSize)
+	if len(a) != 20 || len(b) != 20 {
+		t.Fatal(len(a))
+	}
+	changed := 0
+	for i := range a {
+		if a[i] != b[i] {
+			changed++
+			if err := dst.Patch(blockSize, i, src.Block(blockSize, i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if changed == 0 || changed == len(a) {
+		t.Log(changed)
+		t.Fail()
+	}
+	if !bytes.Equal(src.ToBytes(), dst.ToBytes()) {
+		t.Fail()
+	}
+	if err := dst.Patch(blockSize, 19, make([]uint32, blockSize)); err == nil || errors.Is(err, ErrIncompatible) {
+		t.Fail()
+	}
+	if dst.WordChecksums(0) != nil || dst.Block(0, 0) != nil || dst.Patch(0, 0, nil) == nil {
+		t.Fail()
+	}
+	if err := dst.Patch(blockSize, 20, nil); err == nil {
+		t.Fail()
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := New(10000, 4)
+	for i := 0; i < 100; i++ {
+		old.AddInt(i)
+	}
+	f := old.Clone()
+	for i := 100; i < 110; i++ {
+		f.AddInt(i)
+	}
+	indices, words, err := f.Diff(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) == 0 || len(indices) > 40 {
+		t.Log(len(indices))
+		t.Fail()
+	}
+	if err := old.ApplyDiff(indices, words); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old.ToBytes(), f.ToBytes()) {
+		t.Fail()
+	}
+	if indices, _, _ := f.Diff(old); len(indices) != 0 {
+		t.Fail()
+	}
+	if _, _, err := f.Diff(New(1000, 4)); !errors.Is(err, ErrSizeMismatch) {
+		t.Fail()
+	}
+	if err := old.ApplyDiff([]uint32{1000}, []uint32{1}); err == nil {
+		t.Fail()
+	}
+	if err := old.ApplyDiff([]uint32{1}, nil); err == nil || errors.Is(err, ErrIncompatible) {
+		t.Fail()
+	}
+}
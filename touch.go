@@ -0,0 +1,21 @@
+package bloomfilter
+
+import (
+	"os"
+)
+
+// zero is written into the buckets by Touch. It is a variable so the
+// compiler cannot elide the stores.
+var zero uint32
+
+// Touch writes to one word of every memory page backing the bloom filter so
+// the operating system maps them all up front instead of on first use.
+// It does not change the contents of the filter.
+func (bf *BloomFilter) Touch() {
+	bf.lock.Lock()
+	defer bf.lock.Unlock()
+	var stride = os.Getpagesize() / 4
+	for i := 0; i < len(bf.buckets); i += stride {
+		bf.buckets[i] |= zero
+	}
+}
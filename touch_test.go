@@ -0,0 +1,39 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTouch(t *testing.T) {
+	f := New(100000, 4)
+	f.Add([]byte("abc"))
+	bb := f.ToBytes()
+	f.Touch()
+	if !bytes.Equal(bb, f.ToBytes()) {
+		t.Fail()
+	}
+}
+
+func benchmarkFirstTest(b *testing.B, touch bool) {
+	m, k := EstimateParameters(1000000, 1e-4)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		f := New(m, k)
+		if touch {
+			f.Touch()
+		}
+		b.StartTimer()
+		for j := 0; j < 1000; j++ {
+			f.TestInt(j)
+		}
+	}
+}
+
+func BenchmarkFirstTest(b *testing.B) {
+	benchmarkFirstTest(b, false)
+}
+
+func BenchmarkFirstTestTouched(b *testing.B) {
+	benchmarkFirstTest(b, true)
+}
@@ -0,0 +1,110 @@
+package bloomfilter
+
+import (
+	"bufio"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Load reads a bloom filter in the aligned format, as written by ToAlignedBytes,
+// MarshalBinary or WriteWithMeta, from the file at path.
+func Load(path string) (*BloomFilter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	bf, _, err := ReadWithMeta(bufio.NewReader(f))
+	return bf, err
+}
+
+// Watcher polls a bloom filter file and reloads it with Load whenever its
+// modification time or size changes. Current returns the latest filter
+// without locking. A file that fails to load, for example because it is
+// still being written, is retried on the next poll while Current keeps
+// returning the previous filter; writers should rename complete files into
+// place to avoid this.
+type Watcher struct {
+	path    string
+	current atomic.Value // *BloomFilter
+	err     atomic.Value // errorValue
+	modTime time.Time
+	size    int64
+	stop    chan struct{}
+	closed  sync.Once
+	done    sync.WaitGroup
+}
+
+// NewWatcher loads the bloom filter at path and starts polling it every
+// interval, or every second if interval is not positive.
+func NewWatcher(path string, interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	var w = &Watcher{path: path, stop: make(chan struct{})}
+	if err := w.reload(); err != nil {
+		return nil, err
+	}
+	w.done.Add(1)
+	go w.poll(interval)
+	return w, nil
+}
+
+// Current returns the most recently loaded bloom filter
+func (w *Watcher) Current() *BloomFilter {
+	return w.current.Load().(*BloomFilter)
+}
+
+// Err returns the error from the most recent failed reload, or nil if the
+// most recent reload succeeded
+func (w *Watcher) Err() error {
+	var v, _ = w.err.Load().(errorValue)
+	return v.err
+}
+
+// errorValue wraps an error so errors of different types can share an atomic.Value
+type errorValue struct {
+	err error
+}
+
+// Close stops polling. It is safe to call more than once.
+func (w *Watcher) Close() {
+	w.closed.Do(func() { close(w.stop) })
+	w.done.Wait()
+}
+
+func (w *Watcher) poll(interval time.Duration) {
+	defer w.done.Done()
+	var t = time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-t.C:
+			w.reload()
+		}
+	}
+}
+
+// reload loads the file if it changed since the last successful load
+func (w *Watcher) reload() error {
+	fi, err := os.Stat(w.path)
+	if err == nil && fi.ModTime().Equal(w.modTime) && fi.Size() == w.size {
+		return nil
+	}
+	var bf *BloomFilter
+	if err == nil {
+		bf, err = Load(w.path)
+	}
+	if err != nil {
+		w.err.Store(errorValue{err})
+		return err
+	}
+	w.modTime, w.size = fi.ModTime(), fi.Size()
+	w.current.Store(bf)
+	w.err.Store(errorValue{})
+	return nil
+}
@@ -0,0 +1,83 @@
+package bloomfilter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeAligned(t *testing.T, path string, bf *BloomFilter, mtime time.Time) {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bf.ToAlignedBytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tmp, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter")
+	f := New(1000, 4)
+	f.Add([]byte("old"))
+	now := time.Now()
+	writeAligned(t, path, f, now)
+
+	w, err := NewWatcher(path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if !w.Current().Test([]byte("old")) || w.Current().Test([]byte("new")) {
+		t.Fail()
+	}
+
+	f = New(1000, 4)
+	f.Add([]byte("new"))
+	writeAligned(t, path, f, now.Add(time.Second))
+	for deadline := time.Now().Add(5 * time.Second); !w.Current().Test([]byte("new")); {
+		if time.Now().After(deadline) {
+			t.Fatal("filter was not reloaded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if w.Current().Test([]byte("old")) || w.Err() != nil {
+		t.Fail()
+	}
+
+	// a truncated file keeps the previous filter
+	if err := ioutil.WriteFile(path, f.ToAlignedBytes()[:30], 0644); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); w.Err() == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("truncated file was not noticed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !w.Current().Test([]byte("new")) {
+		t.Fail()
+	}
+}
+
+func TestNewWatcherMissing(t *testing.T) {
+	if _, err := NewWatcher(filepath.Join(t.TempDir(), "missing"), 0); err == nil {
+		t.Fail()
+	}
+}
+
+func TestWatcherCloseTwice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter")
+	writeAligned(t, path, New(1000, 4), time.Now())
+	w, err := NewWatcher(path, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	w.Close()
+}